@@ -52,6 +52,10 @@ const (
 	metricsEndpoint = "/metrics"
 	metricsPort     = 8080
 	grpcPort        = 6565
+
+	// placementNotificationsEndpoint is served on the metrics port when PLACEMENT_NOTIFICATIONS_ENABLED is set
+	// The metrics port serves plain HTTP, so TLS for SNS must be terminated in front of it
+	placementNotificationsEndpoint = "/gamelift/placement-notifications"
 )
 
 var (
@@ -140,7 +144,13 @@ func main() {
 	}
 	gameLiftClient := gamelift.NewFromConfig(conf)
 
-	sessiondsm.RegisterSessionDsmServer(grpcServer, server.NewSessionDSM(sessionClient, gameLiftClient))
+	sessionDsm, err := server.NewSessionDSM(sessionClient, gameLiftClient)
+	if err != nil {
+		logrus.Fatalf("failed to create session dsm: %v", err)
+	}
+	sessionDsm.DSInformationClient = server.NewDSInformationClient(sessionClient)
+	sessionDsm.Start(ctx)
+	sessiondsm.RegisterSessionDsmServer(grpcServer, sessionDsm)
 
 	// Enable gRPC Reflection
 	reflection.Register(grpcServer)
@@ -157,12 +167,19 @@ func main() {
 		prometheusCollectors.NewProcessCollector(prometheusCollectors.ProcessCollectorOpts{}),
 		srvMetrics,
 	)
+	prometheusRegistry.MustRegister(server.Collectors()...)
 
 	go func() {
 		http.Handle(metricsEndpoint, promhttp.HandlerFor(prometheusRegistry, promhttp.HandlerOpts{}))
+		if sessionDsm.PlacementNotifications != nil {
+			http.Handle(placementNotificationsEndpoint, sessionDsm.PlacementNotificationHandler())
+		}
 		log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", metricsPort), nil))
 	}()
 	logrus.Infof("serving prometheus metrics at: (:%d%s)", metricsPort, metricsEndpoint)
+	if sessionDsm.PlacementNotifications != nil {
+		logrus.Infof("serving placement notifications at: (:%d%s)", metricsPort, placementNotificationsEndpoint)
+	}
 
 	// Set Tracer Provider
 	tracerProvider, err := common.NewTracerProvider(serviceName, environment, id)
@@ -5,6 +5,7 @@
 package common
 
 import (
+	"fmt"
 	"os"
 	"strconv"
 )
@@ -26,3 +27,18 @@ func GetEnvInt(key string, fallback int) int {
 
 	return val
 }
+
+// GetEnvFloat returns the fallback when the variable is unset or empty, and an error when it is not a number
+func GetEnvFloat(key string, fallback float64) (float64, error) {
+	str := GetEnv(key, "")
+	if str == "" {
+		return fallback, nil
+	}
+
+	val, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a number, got %q", key, str)
+	}
+
+	return val, nil
+}
@@ -117,6 +117,35 @@ func TestGetEnvInt(t *testing.T) {
 	}
 }
 
+func TestGetEnvFloat(t *testing.T) {
+	tests := []struct {
+		name     string
+		envValue *string
+		want     float64
+		wantErr  bool
+	}{
+		{name: "Unset envar", envValue: nil, want: 2.5},
+		{name: "Empty value envar", envValue: pstr(""), want: 2.5},
+		{name: "Fractional value envar", envValue: pstr("1.5"), want: 1.5},
+		{name: "Integer value envar", envValue: pstr("3"), want: 3},
+		{name: "Non numeric value envar", envValue: pstr("one"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.envValue != nil {
+				t.Setenv("FACTOR", *tt.envValue)
+			}
+			got, err := GetEnvFloat("FACTOR", 2.5)
+			if tt.wantErr {
+				assert.EqualError(t, err, `FACTOR must be a number, got "one"`)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func pstr(s string) *string {
 	return &s
 }
@@ -0,0 +1,215 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package gameproperties
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GameLift limits on the game properties of a single game session
+const (
+	MaxProperties  = 16
+	MaxKeyLength   = 32
+	MaxValueLength = 96
+)
+
+// Keys of the game properties the Session DSM always sends, which a mapping can't use
+const (
+	ClientVersionKey = "clientVersion"
+	GameModeKey      = "gameMode"
+	SessionSecretKey = "sessionSecret"
+)
+
+// MaxMappedProperties is the number of properties left to a mapping next to the ones that are always sent
+const MaxMappedProperties = MaxProperties - 3
+
+// Mapping declares which values of the session data are sent to GameLift as game properties
+type Mapping struct {
+	Properties []Property `yaml:"properties"`
+}
+
+// Property maps a JSON path in the session data to a game property key
+type Property struct {
+	Key string `yaml:"key"`
+
+	// Path is a dot-separated JSON path into the session data, e.g. "teams.0.user_ids"
+	// Numeric segments index into arrays
+	Path string `yaml:"path"`
+
+	// Default is used when the path is not present in the session data
+	// Leave empty to omit the property instead
+	Default string `yaml:"default"`
+
+	// Required fails the request when the path is not present and no default is set
+	Required bool `yaml:"required"`
+
+	// Length sends the number of elements of the array or object at the path instead of its content,
+	// e.g. to send team sizes
+	Length bool `yaml:"length"`
+}
+
+// Value is a game property resolved from the session data
+type Value struct {
+	Key   string
+	Value string
+}
+
+// Load reads a game property mapping from a YAML or JSON file
+func Load(filePath string) (*Mapping, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read game property mapping %s: %w", filePath, err)
+	}
+
+	return Parse(content)
+}
+
+// Parse decodes a game property mapping from YAML or JSON content and validates its properties
+func Parse(content []byte) (*Mapping, error) {
+	var mapping Mapping
+	if err := yaml.Unmarshal(content, &mapping); err != nil {
+		return nil, fmt.Errorf("failed to parse game property mapping: %w", err)
+	}
+
+	if len(mapping.Properties) > MaxMappedProperties {
+		return nil, fmt.Errorf("game property mapping has %d properties, at most %d fit next to %s, %s and %s", len(mapping.Properties), MaxMappedProperties, ClientVersionKey, GameModeKey, SessionSecretKey)
+	}
+
+	keys := make(map[string]bool, len(mapping.Properties))
+	for i, property := range mapping.Properties {
+		if err := property.validate(); err != nil {
+			return nil, fmt.Errorf("invalid game property %d: %w", i, err)
+		}
+		if keys[property.Key] {
+			return nil, fmt.Errorf("invalid game property %d: duplicate key %q", i, property.Key)
+		}
+		keys[property.Key] = true
+	}
+
+	return &mapping, nil
+}
+
+// Resolve looks up every mapped property in the session data
+// Properties whose path is missing are omitted, unless they have a default or are required
+func (m *Mapping) Resolve(sessionData string) ([]Value, error) {
+	if m == nil || len(m.Properties) == 0 {
+		return nil, nil
+	}
+
+	var document interface{}
+	if strings.TrimSpace(sessionData) != "" {
+		decoder := json.NewDecoder(strings.NewReader(sessionData))
+		decoder.UseNumber()
+		if err := decoder.Decode(&document); err != nil {
+			return nil, fmt.Errorf("session data is not valid JSON: %w", err)
+		}
+	}
+
+	values := make([]Value, 0, len(m.Properties))
+	for _, property := range m.Properties {
+		value, found, err := property.resolve(document)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			if property.Default != "" {
+				values = append(values, Value{Key: property.Key, Value: property.Default})
+				continue
+			}
+			if property.Required {
+				return nil, fmt.Errorf("session data has no value at %q for required game property %q", property.Path, property.Key)
+			}
+			continue
+		}
+
+		values = append(values, Value{Key: property.Key, Value: value})
+	}
+
+	return values, nil
+}
+
+func (p Property) validate() error {
+	if p.Key == "" {
+		return errors.New("key is required")
+	}
+	switch p.Key {
+	case ClientVersionKey, GameModeKey, SessionSecretKey:
+		return fmt.Errorf("key %q is reserved", p.Key)
+	}
+	if utf8.RuneCountInString(p.Key) > MaxKeyLength {
+		return fmt.Errorf("key %q is longer than %d characters", p.Key, MaxKeyLength)
+	}
+	if p.Path == "" {
+		return fmt.Errorf("path is required for key %q", p.Key)
+	}
+	if utf8.RuneCountInString(p.Default) > MaxValueLength {
+		return fmt.Errorf("default of key %q is longer than %d characters", p.Key, MaxValueLength)
+	}
+
+	return nil
+}
+
+func (p Property) resolve(document interface{}) (string, bool, error) {
+	node := document
+	for _, segment := range strings.Split(p.Path, ".") {
+		switch current := node.(type) {
+		case map[string]interface{}:
+			child, ok := current[segment]
+			if !ok {
+				return "", false, nil
+			}
+			node = child
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(current) {
+				return "", false, nil
+			}
+			node = current[index]
+		default:
+			return "", false, nil
+		}
+	}
+
+	if p.Length {
+		switch current := node.(type) {
+		case map[string]interface{}:
+			return strconv.Itoa(len(current)), true, nil
+		case []interface{}:
+			return strconv.Itoa(len(current)), true, nil
+		default:
+			return "", false, fmt.Errorf("game property %q expects an array or object at %q", p.Key, p.Path)
+		}
+	}
+
+	switch current := node.(type) {
+	case nil:
+		return "", false, nil
+	case string:
+		return current, true, nil
+	case json.Number:
+		return current.String(), true, nil
+	case bool:
+		return strconv.FormatBool(current), true, nil
+	default:
+		// Arrays and objects are sent as compact JSON
+		var buffer bytes.Buffer
+		encoder := json.NewEncoder(&buffer)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(current); err != nil {
+			return "", false, fmt.Errorf("failed to encode game property %q: %w", p.Key, err)
+		}
+
+		return strings.TrimSuffix(buffer.String(), "\n"), true, nil
+	}
+}
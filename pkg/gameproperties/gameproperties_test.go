@@ -0,0 +1,116 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package gameproperties
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMapping = `
+properties:
+  - key: map
+    path: match_attributes.map
+  - key: ruleset
+    path: match_attributes.ruleset
+    default: standard
+  - key: teamSize
+    path: teams.0.user_ids
+    length: true
+  - key: ranked
+    path: match_attributes.ranked
+  - key: minSkill
+    path: match_attributes.skill.min
+  - key: regions
+    path: match_attributes.regions
+`
+
+const testSessionData = `{
+  "match_attributes": {"map": "dust", "ranked": true, "skill": {"min": 1200}, "regions": ["us-west-2", "eu-west-1"]},
+  "teams": [{"user_ids": ["a", "b", "c"]}, {"user_ids": ["d", "e", "f"]}]
+}`
+
+func TestResolve(t *testing.T) {
+	mapping, err := Parse([]byte(testMapping))
+	require.NoError(t, err)
+
+	values, err := mapping.Resolve(testSessionData)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Value{
+		{Key: "map", Value: "dust"},
+		{Key: "ruleset", Value: "standard"},
+		{Key: "teamSize", Value: "3"},
+		{Key: "ranked", Value: "true"},
+		{Key: "minSkill", Value: "1200"},
+		{Key: "regions", Value: `["us-west-2","eu-west-1"]`},
+	}, values)
+}
+
+func TestResolveMissingValues(t *testing.T) {
+	mapping, err := Parse([]byte(`
+properties:
+  - key: map
+    path: match_attributes.map
+  - key: mode
+    path: match_attributes.mode
+    required: true
+`))
+	require.NoError(t, err)
+
+	_, err = mapping.Resolve(`{"match_attributes": {"mode": "ffa"}}`)
+	require.NoError(t, err)
+
+	_, err = mapping.Resolve(`{"match_attributes": {}}`)
+	assert.ErrorContains(t, err, "required game property")
+
+	_, err = mapping.Resolve(`not json`)
+	assert.Error(t, err)
+
+	var nilMapping *Mapping
+	values, err := nilMapping.Resolve(`not json`)
+	assert.NoError(t, err)
+	assert.Empty(t, values)
+}
+
+func manyProperties(count int) string {
+	properties := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		properties = append(properties, fmt.Sprintf("{key: k%d, path: p}", i))
+	}
+
+	return strings.Join(properties, ", ")
+}
+
+func TestParseMaxProperties(t *testing.T) {
+	mapping, err := Parse([]byte("properties: [" + manyProperties(MaxMappedProperties) + "]"))
+	require.NoError(t, err)
+	assert.Len(t, mapping.Properties, MaxProperties-3)
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "Missing key", content: "properties: [{path: map}]"},
+		{name: "Missing path", content: "properties: [{key: map}]"},
+		{name: "Key too long", content: "properties: [{key: " + strings.Repeat("k", MaxKeyLength+1) + ", path: map}]"},
+		{name: "Duplicate key", content: "properties: [{key: map, path: map}, {key: map, path: other}]"},
+		{name: "Too many properties", content: "properties: [" + manyProperties(MaxMappedProperties+1) + "]"},
+		{name: "Reserved key", content: "properties: [{key: gameMode, path: mode}]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.content))
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,320 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+// Package latency reads player latencies from AGS session data, in any of the shapes AGS and game clients send them
+package latency
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	// MaxEntries is the most player latencies sent to GameLift with a single request
+	MaxEntries = 100
+
+	// MaxIdentifierLength is the longest player ID or region name GameLift accepts in a player latency
+	MaxIdentifierLength = 1024
+
+	// DefaultMaxLatencyMs is the highest latency kept when Ingester.MaxLatencyMs is not set
+	// Higher values are almost always failed QoS probes rather than real measurements
+	DefaultMaxLatencyMs = 5000
+)
+
+// Session data fields latencies are read from
+const (
+	// GameLiftLatenciesField holds {"<player ID>": {"<region>": <ms>}}
+	GameLiftLatenciesField = "gamelift_latencies"
+
+	// PlayerLatenciesField holds the same map, or an array of such maps
+	PlayerLatenciesField = "player_latencies"
+
+	// MemberLatenciesAttribute is the member attribute holding {"<region>": <ms>} in AGS session members,
+	// as set from matchmaking and QoS results
+	MemberLatenciesAttribute = "latencies"
+)
+
+// ErrNoLatencies is returned when the session data holds no usable player latency
+var ErrNoLatencies = errors.New("no player latencies found in session data")
+
+// Entry is the latency of one player to one GameLift location
+type Entry struct {
+	PlayerId  string
+	Region    string
+	LatencyMs float32
+}
+
+// Ingester reads, cleans up and caps the player latencies in session data
+type Ingester struct {
+	// RegionMap renames AGS region names to GameLift location names, e.g. "us-east" to "us-east-1"
+	// Keys are matched without regard to case, regions that aren't in the map are used as is
+	RegionMap map[string]string
+
+	// MaxLatencyMs drops latencies above this value
+	// Zero uses DefaultMaxLatencyMs
+	MaxLatencyMs float64
+
+	// OutlierFactor drops latencies far above those of the other players to the same region:
+	// above the third quartile plus this many interquartile ranges
+	// Only applied to regions with at least minOutlierSamples latencies, and zero disables outlier removal
+	OutlierFactor float64
+}
+
+// minOutlierSamples is the fewest latencies to one region that quartiles are computed from
+const minOutlierSamples = 4
+
+// ParseRegionMap reads a region map from its configuration value, e.g. "us-east=us-east-1,eu-central=eu-central-1"
+func ParseRegionMap(value string) (map[string]string, error) {
+	regionMap := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		from, to, ok := strings.Cut(pair, "=")
+		from, to = normalizeRegion(from), strings.TrimSpace(to)
+		if !ok || from == "" || to == "" {
+			return nil, fmt.Errorf("invalid region mapping %q, expected <AGS region>=<GameLift location>", pair)
+		}
+		regionMap[from] = to
+	}
+
+	return regionMap, nil
+}
+
+// Location returns the GameLift location name of an AGS region name
+func (i Ingester) Location(region string) string {
+	if location, ok := i.RegionMap[normalizeRegion(region)]; ok {
+		return location
+	}
+
+	return strings.TrimSpace(region)
+}
+
+// Extract returns the player latencies found in the session data
+// gamelift_latencies is read first, then player_latencies, then the latencies attribute of each member
+// A player and region pair found more than once keeps its first value
+func (i Ingester) Extract(sessionData string) ([]Entry, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(sessionData), &fields); err != nil {
+		return nil, fmt.Errorf("session data is not a JSON object: %w", err)
+	}
+
+	collector := newCollector(i)
+	collector.addPlayerMap(fields[GameLiftLatenciesField])
+	collector.addPlayerLatencies(fields[PlayerLatenciesField])
+	collector.addMembers(fields["members"])
+
+	entries := collector.entries
+	if i.OutlierFactor > 0 {
+		entries = dropOutliers(entries, i.OutlierFactor)
+	}
+	entries = capEntries(entries, MaxEntries)
+
+	if len(entries) == 0 {
+		return nil, ErrNoLatencies
+	}
+
+	return entries, nil
+}
+
+type entryKey struct {
+	playerId string
+	region   string
+}
+
+// collector accumulates valid entries, without duplicates
+type collector struct {
+	ingester     Ingester
+	maxLatencyMs float64
+	seen         map[entryKey]bool
+	entries      []Entry
+}
+
+func newCollector(ingester Ingester) *collector {
+	maxLatencyMs := ingester.MaxLatencyMs
+	if maxLatencyMs <= 0 {
+		maxLatencyMs = DefaultMaxLatencyMs
+	}
+
+	return &collector{
+		ingester:     ingester,
+		maxLatencyMs: maxLatencyMs,
+		seen:         make(map[entryKey]bool),
+	}
+}
+
+// addPlayerLatencies reads either a single player map or an array of player maps
+func (c *collector) addPlayerLatencies(content json.RawMessage) {
+	var playerMaps []json.RawMessage
+	if err := json.Unmarshal(content, &playerMaps); err != nil {
+		c.addPlayerMap(content)
+		return
+	}
+
+	for _, playerMap := range playerMaps {
+		c.addPlayerMap(playerMap)
+	}
+}
+
+// addPlayerMap reads {"<player ID>": {"<region>": <ms>}}
+func (c *collector) addPlayerMap(content json.RawMessage) {
+	var players map[string]json.RawMessage
+	if err := json.Unmarshal(content, &players); err != nil {
+		return
+	}
+
+	for _, playerId := range sortedKeys(players) {
+		c.addRegionMap(playerId, players[playerId])
+	}
+}
+
+// addMembers reads the latencies attribute of every AGS session member
+func (c *collector) addMembers(content json.RawMessage) {
+	var members []json.RawMessage
+	if err := json.Unmarshal(content, &members); err != nil {
+		return
+	}
+
+	for _, content := range members {
+		var member struct {
+			ID         string                     `json:"id"`
+			Attributes map[string]json.RawMessage `json:"attributes"`
+		}
+		if err := json.Unmarshal(content, &member); err != nil {
+			continue
+		}
+
+		c.addRegionMap(member.ID, member.Attributes[MemberLatenciesAttribute])
+	}
+}
+
+// addRegionMap reads {"<region>": <ms>} for one player
+func (c *collector) addRegionMap(playerId string, content json.RawMessage) {
+	if playerId == "" || len(playerId) > MaxIdentifierLength {
+		return
+	}
+
+	var regions map[string]json.RawMessage
+	if err := json.Unmarshal(content, &regions); err != nil {
+		return
+	}
+
+	for _, region := range sortedKeys(regions) {
+		var latencyMs float64
+		if err := json.Unmarshal(regions[region], &latencyMs); err != nil {
+			continue
+		}
+		c.add(playerId, region, latencyMs)
+	}
+}
+
+func (c *collector) add(playerId, region string, latencyMs float64) {
+	// Only the lookup is case-insensitive, custom Anywhere locations such as custom-MyDC are case-sensitive in GameLift
+	region = c.ingester.Location(region)
+	if region == "" || len(region) > MaxIdentifierLength {
+		return
+	}
+
+	if math.IsNaN(latencyMs) || math.IsInf(latencyMs, 0) || latencyMs <= 0 || latencyMs > c.maxLatencyMs {
+		return
+	}
+
+	key := entryKey{playerId: playerId, region: normalizeRegion(region)}
+	if c.seen[key] {
+		return
+	}
+	c.seen[key] = true
+
+	c.entries = append(c.entries, Entry{PlayerId: playerId, Region: region, LatencyMs: float32(latencyMs)})
+}
+
+// dropOutliers removes latencies above the upper fence of their region, keeping the order of the rest
+func dropOutliers(entries []Entry, factor float64) []Entry {
+	latenciesByRegion := make(map[string][]float64)
+	for _, entry := range entries {
+		latenciesByRegion[entry.Region] = append(latenciesByRegion[entry.Region], float64(entry.LatencyMs))
+	}
+
+	fences := make(map[string]float64)
+	for region, latencies := range latenciesByRegion {
+		if len(latencies) < minOutlierSamples {
+			continue
+		}
+
+		sort.Float64s(latencies)
+		q1, q3 := quantile(latencies, 0.25), quantile(latencies, 0.75)
+		fences[region] = q3 + factor*(q3-q1)
+	}
+
+	kept := entries[:0:0]
+	for _, entry := range entries {
+		if fence, ok := fences[entry.Region]; ok && float64(entry.LatencyMs) > fence {
+			continue
+		}
+		kept = append(kept, entry)
+	}
+
+	return kept
+}
+
+// capEntries keeps at most limit entries, taking every player's best regions first,
+// so a player with many regions can't push out all the regions of the others
+func capEntries(entries []Entry, limit int) []Entry {
+	if len(entries) <= limit {
+		return entries
+	}
+
+	byPlayer := make(map[string][]Entry)
+	var playerIds []string
+	for _, entry := range entries {
+		if _, ok := byPlayer[entry.PlayerId]; !ok {
+			playerIds = append(playerIds, entry.PlayerId)
+		}
+		byPlayer[entry.PlayerId] = append(byPlayer[entry.PlayerId], entry)
+	}
+	for _, playerEntries := range byPlayer {
+		sort.SliceStable(playerEntries, func(i, j int) bool {
+			return playerEntries[i].LatencyMs < playerEntries[j].LatencyMs
+		})
+	}
+
+	capped := make([]Entry, 0, limit)
+	for rank := 0; len(capped) < limit; rank++ {
+		for _, playerId := range playerIds {
+			if rank < len(byPlayer[playerId]) && len(capped) < limit {
+				capped = append(capped, byPlayer[playerId][rank])
+			}
+		}
+	}
+
+	return capped
+}
+
+// quantile interpolates the q quantile of sorted values
+func quantile(sorted []float64, q float64) float64 {
+	position := q * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(position-float64(lower))
+}
+
+func normalizeRegion(region string) string {
+	return strings.ToLower(strings.TrimSpace(region))
+}
+
+func sortedKeys(values map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
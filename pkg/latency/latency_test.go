@@ -0,0 +1,182 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package latency
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name        string
+		sessionData string
+		want        []Entry
+	}{
+		{
+			name:        "GameLift latencies",
+			sessionData: `{"gamelift_latencies":{"a":{"us-west-2":42.5,"us-east-1":80}}}`,
+			want: []Entry{
+				{PlayerId: "a", Region: "us-east-1", LatencyMs: 80},
+				{PlayerId: "a", Region: "us-west-2", LatencyMs: 42.5},
+			},
+		},
+		{
+			name:        "Player latencies map",
+			sessionData: `{"player_latencies":{"a":{"us-west-2":42.5}}}`,
+			want:        []Entry{{PlayerId: "a", Region: "us-west-2", LatencyMs: 42.5}},
+		},
+		{
+			name:        "Player latencies array",
+			sessionData: `{"player_latencies":[{"a":{"us-west-2":42.5}},{"b":{"us-west-2":60}}]}`,
+			want: []Entry{
+				{PlayerId: "a", Region: "us-west-2", LatencyMs: 42.5},
+				{PlayerId: "b", Region: "us-west-2", LatencyMs: 60},
+			},
+		},
+		{
+			name:        "Member attributes",
+			sessionData: `{"members":[{"id":"a","status":"JOINED","attributes":{"latencies":{"us-west-2":42}}},{"id":"b","attributes":{}}]}`,
+			want:        []Entry{{PlayerId: "a", Region: "us-west-2", LatencyMs: 42}},
+		},
+		{
+			name:        "First shape wins for duplicates",
+			sessionData: `{"gamelift_latencies":{"a":{"us-west-2":42}},"members":[{"id":"a","attributes":{"latencies":{"us-west-2":99,"eu-west-1":150}}}]}`,
+			want: []Entry{
+				{PlayerId: "a", Region: "us-west-2", LatencyMs: 42},
+				{PlayerId: "a", Region: "eu-west-1", LatencyMs: 150},
+			},
+		},
+		{
+			name:        "Invalid values",
+			sessionData: `{"gamelift_latencies":{"a":{"us-west-2":0,"us-east-1":-5,"eu-west-1":"fast","eu-central-1":99999,"ap-south-1":120},"":{"us-west-2":10}}}`,
+			want:        []Entry{{PlayerId: "a", Region: "ap-south-1", LatencyMs: 120}},
+		},
+		{
+			name:        "Wrong types are skipped",
+			sessionData: `{"gamelift_latencies":[1,2],"player_latencies":"none","members":[{"id":"a","attributes":{"latencies":{"us-west-2":30}}},7]}`,
+			want:        []Entry{{PlayerId: "a", Region: "us-west-2", LatencyMs: 30}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := Ingester{}.Extract(tt.sessionData)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, entries)
+		})
+	}
+}
+
+func TestExtractWithoutLatencies(t *testing.T) {
+	_, err := Ingester{}.Extract(`{"teams":[]}`)
+	assert.ErrorIs(t, err, ErrNoLatencies)
+
+	_, err = Ingester{}.Extract(`not json`)
+	assert.Error(t, err)
+}
+
+func TestExtractMapsRegions(t *testing.T) {
+	regionMap, err := ParseRegionMap("US-East = us-east-1, eu-central=eu-central-1, my-dc=custom-MyDC")
+	require.NoError(t, err)
+
+	// The lookup ignores case, the custom location keeps its own
+	entries, err := Ingester{RegionMap: regionMap}.Extract(`{"gamelift_latencies":{"a":{"MY-DC":20,"us-east":40,"us-west-2":60}}}`)
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{
+		{PlayerId: "a", Region: "custom-MyDC", LatencyMs: 20},
+		{PlayerId: "a", Region: "us-east-1", LatencyMs: 40},
+		{PlayerId: "a", Region: "us-west-2", LatencyMs: 60},
+	}, entries)
+
+	_, err = ParseRegionMap("us-east")
+	assert.Error(t, err)
+}
+
+func TestExtractDropsOutliers(t *testing.T) {
+	sessionData := `{"gamelift_latencies":{
+		"a":{"us-west-2":40},
+		"b":{"us-west-2":45},
+		"c":{"us-west-2":50},
+		"d":{"us-west-2":55},
+		"e":{"us-west-2":900}
+	}}`
+
+	entries, err := Ingester{OutlierFactor: 3}.Extract(sessionData)
+	require.NoError(t, err)
+	assert.Len(t, entries, 4)
+	for _, entry := range entries {
+		assert.NotEqual(t, "e", entry.PlayerId)
+	}
+
+	entries, err = Ingester{}.Extract(sessionData)
+	require.NoError(t, err)
+	assert.Len(t, entries, 5)
+}
+
+func TestExtractCapsEntries(t *testing.T) {
+	var players []string
+	for player := 0; player < 30; player++ {
+		var regions []string
+		for region := 0; region < 10; region++ {
+			regions = append(regions, fmt.Sprintf(`"region-%d":%d`, region, 10+region))
+		}
+		players = append(players, fmt.Sprintf(`"player-%02d":{%s}`, player, strings.Join(regions, ",")))
+	}
+
+	entries, err := Ingester{}.Extract(`{"gamelift_latencies":{` + strings.Join(players, ",") + `}}`)
+	require.NoError(t, err)
+	require.Len(t, entries, MaxEntries)
+
+	// Every player keeps their best regions
+	regionsByPlayer := make(map[string][]string)
+	for _, entry := range entries {
+		regionsByPlayer[entry.PlayerId] = append(regionsByPlayer[entry.PlayerId], entry.Region)
+	}
+	assert.Len(t, regionsByPlayer, 30)
+	for _, regions := range regionsByPlayer {
+		assert.Contains(t, regions, "region-0")
+	}
+}
+
+func FuzzExtract(f *testing.F) {
+	f.Add(`{"gamelift_latencies":{"a":{"us-west-2":42.5}}}`)
+	f.Add(`{"player_latencies":[{"a":{"us-west-2":42.5}},{"b":{"US-EAST":1e308}}]}`)
+	f.Add(`{"members":[{"id":"a","attributes":{"latencies":{"us-west-2":-1,"eu-west-1":12}}}]}`)
+	f.Add(`{"gamelift_latencies":{"a":{"x":1},"b":{"x":1},"c":{"x":1},"d":{"x":1},"e":{"x":1e9}}}`)
+	f.Add(`[]`)
+
+	ingester := Ingester{RegionMap: map[string]string{"us-east": "us-east-1"}, OutlierFactor: 3}
+	f.Fuzz(func(t *testing.T, sessionData string) {
+		entries, err := ingester.Extract(sessionData)
+		if err != nil {
+			assert.Empty(t, entries)
+			return
+		}
+
+		assert.NotEmpty(t, entries)
+		assert.LessOrEqual(t, len(entries), MaxEntries)
+
+		seen := make(map[[2]string]bool)
+		for _, entry := range entries {
+			assert.NotEmpty(t, entry.PlayerId)
+			assert.NotEmpty(t, entry.Region)
+			assert.LessOrEqual(t, len(entry.PlayerId), MaxIdentifierLength)
+			assert.LessOrEqual(t, len(entry.Region), MaxIdentifierLength)
+			assert.False(t, math.IsNaN(float64(entry.LatencyMs)) || math.IsInf(float64(entry.LatencyMs), 0))
+			assert.Greater(t, entry.LatencyMs, float32(0))
+			assert.LessOrEqual(t, entry.LatencyMs, float32(DefaultMaxLatencyMs))
+
+			key := [2]string{entry.PlayerId, entry.Region}
+			assert.False(t, seen[key], "duplicate entry %v", key)
+			seen[key] = true
+		}
+	})
+}
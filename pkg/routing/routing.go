@@ -0,0 +1,226 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package routing
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Variant names the side of a canary split a session was sent to
+type Variant string
+
+const (
+	VariantStable Variant = "stable"
+	VariantCanary Variant = "canary"
+)
+
+// Path names the way a session is created, which decides whether the alias or the queue of a target is used
+type Path string
+
+const (
+	// PathSync is CreateGameSession, which uses the alias
+	PathSync Path = "sync"
+	// PathAsync is CreateGameSessionAsync, which uses the queue
+	PathAsync Path = "async"
+)
+
+// canaryBuckets is the resolution of canary percentages, so 0.01% steps can be used
+const canaryBuckets = 10000
+
+// Table maps incoming session requests to GameLift targets
+// Routes are evaluated in file order and the first matching route wins
+type Table struct {
+	Routes []Route `yaml:"routes"`
+}
+
+// Route pairs a request matcher with the GameLift target it resolves to
+type Route struct {
+	Match  Match  `yaml:"match"`
+	Target Target `yaml:",inline"`
+}
+
+// Match selects requests by their AGS attributes
+// Empty fields match anything, non-empty fields are glob patterns, e.g. "1.2.*"
+type Match struct {
+	Namespace     string `yaml:"namespace"`
+	Deployment    string `yaml:"deployment"`
+	GameMode      string `yaml:"game_mode"`
+	ClientVersion string `yaml:"client_version"`
+}
+
+// Target is the GameLift destination for a matched request
+// AliasId and Locations are used by CreateGameSession, Queue is used by CreateGameSessionAsync
+type Target struct {
+	AliasId   string   `yaml:"alias_id"`
+	Locations []string `yaml:"locations"`
+	Queue     string   `yaml:"queue"`
+	Canary    *Canary  `yaml:"canary"`
+
+	// Address is what CreateGameSession returns as the server address: ip, dns, or auto to return the DNS name
+	// for fleets with generated TLS certificates
+	// Empty uses the global setting
+	Address string `yaml:"address"`
+
+	// LocationPriority decides whether CreateGameSessionAsync places sessions in the queue's own location order (queue),
+	// in the requested region order first and then the queue's (requested), or only in the requested region order
+	// (requested_only)
+	// Empty uses the global setting
+	LocationPriority string `yaml:"location_priority"`
+
+	// DiscoverFleet sends CreateGameSession to the alias or fleet whose build version matches the client version
+	// instead of AliasId, and requires FLEET_DISCOVERY_ENABLED
+	// Canary sessions are always sent to the canary alias
+	DiscoverFleet bool `yaml:"discover_fleet"`
+}
+
+// Canary sends a share of the sessions of a route to another alias or queue, e.g. during a server rollout
+// Each session is assigned by a hash of its ID, so retries of the same session stay on the same side
+type Canary struct {
+	AliasId string `yaml:"alias_id"`
+	Queue   string `yaml:"queue"`
+
+	// Percent of the sessions sent to the canary, between 0 and 100
+	Percent float64 `yaml:"percent"`
+}
+
+// Request holds the request attributes a route is matched against
+type Request struct {
+	Namespace     string
+	Deployment    string
+	GameMode      string
+	ClientVersion string
+}
+
+// Load reads a routing table from a YAML or JSON file
+func Load(filePath string) (*Table, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read routing table %s: %w", filePath, err)
+	}
+
+	return Parse(content)
+}
+
+// Parse decodes a routing table from YAML or JSON content and validates its routes
+func Parse(content []byte) (*Table, error) {
+	var table Table
+	if err := yaml.Unmarshal(content, &table); err != nil {
+		return nil, fmt.Errorf("failed to parse routing table: %w", err)
+	}
+
+	for i, route := range table.Routes {
+		if err := route.validate(); err != nil {
+			return nil, fmt.Errorf("invalid route %d: %w", i, err)
+		}
+	}
+
+	return &table, nil
+}
+
+// Resolve returns the target of the first route that matches the request
+func (t *Table) Resolve(req Request) (Target, bool) {
+	if t == nil {
+		return Target{}, false
+	}
+
+	for _, route := range t.Routes {
+		if route.Match.matches(req) {
+			return route.Target, true
+		}
+	}
+
+	return Target{}, false
+}
+
+// Split returns the target a session is sent to, with the canary alias and queue in place of the stable ones
+// for the sessions that fall in the canary share
+// A canary without a target for the path, e.g. one that only sets a queue on the sync path, keeps every session on stable
+func (t Target) Split(sessionId string, requestPath Path) (Target, Variant) {
+	canary := t.Canary
+	t.Canary = nil
+	if canary == nil || canary.Percent <= 0 {
+		return t, VariantStable
+	}
+
+	if (requestPath == PathSync && canary.AliasId == "") || (requestPath == PathAsync && canary.Queue == "") {
+		return t, VariantStable
+	}
+
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(sessionId))
+	if float64(hash.Sum64()%canaryBuckets) >= canary.Percent*canaryBuckets/100 {
+		return t, VariantStable
+	}
+
+	// The canary target is always explicit, a discovered fleet would take the canary share out of the rollout
+	t.DiscoverFleet = false
+	if canary.AliasId != "" {
+		t.AliasId = canary.AliasId
+	}
+	if canary.Queue != "" {
+		t.Queue = canary.Queue
+	}
+
+	return t, VariantCanary
+}
+
+func (r Route) validate() error {
+	if r.Target.AliasId == "" && len(r.Target.Locations) == 0 && r.Target.Queue == "" && r.Target.Address == "" && r.Target.LocationPriority == "" && !r.Target.DiscoverFleet {
+		return errors.New("route must set at least one of alias_id, locations, queue, address, location_priority or discover_fleet")
+	}
+
+	switch r.Target.Address {
+	case "", "ip", "dns", "auto":
+	default:
+		return fmt.Errorf("unknown address %q, expected ip, dns or auto", r.Target.Address)
+	}
+
+	switch r.Target.LocationPriority {
+	case "", "queue", "requested", "requested_only":
+	default:
+		return fmt.Errorf("unknown location_priority %q, expected queue, requested or requested_only", r.Target.LocationPriority)
+	}
+
+	if canary := r.Target.Canary; canary != nil {
+		if canary.AliasId == "" && canary.Queue == "" {
+			return errors.New("canary must set at least one of alias_id or queue")
+		}
+		if canary.Percent < 0 || canary.Percent > 100 {
+			return fmt.Errorf("canary percent %v is not between 0 and 100", canary.Percent)
+		}
+	}
+
+	for _, pattern := range []string{r.Match.Namespace, r.Match.Deployment, r.Match.GameMode, r.Match.ClientVersion} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("bad pattern %q: %w", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+func (m Match) matches(req Request) bool {
+	return matchField(m.Namespace, req.Namespace) &&
+		matchField(m.Deployment, req.Deployment) &&
+		matchField(m.GameMode, req.GameMode) &&
+		matchField(m.ClientVersion, req.ClientVersion)
+}
+
+func matchField(pattern, value string) bool {
+	if pattern == "" {
+		return true
+	}
+
+	// Patterns are validated on load, so the error can be ignored here
+	ok, _ := path.Match(pattern, value)
+
+	return ok
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package routing
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTable = `
+routes:
+  - match:
+      namespace: mygame
+      game_mode: ranked
+      client_version: "1.2.*"
+    alias_id: alias-ranked
+    locations: [us-west-2, us-east-1]
+  - match:
+      namespace: mygame
+    alias_id: alias-default
+    queue: default-queue
+`
+
+func TestResolve(t *testing.T) {
+	table, err := Parse([]byte(testTable))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		req     Request
+		want    Target
+		matched bool
+	}{
+		{
+			name:    "Most specific route",
+			req:     Request{Namespace: "mygame", GameMode: "ranked", ClientVersion: "1.2.7"},
+			want:    Target{AliasId: "alias-ranked", Locations: []string{"us-west-2", "us-east-1"}},
+			matched: true,
+		},
+		{
+			name:    "Client version outside pattern falls through",
+			req:     Request{Namespace: "mygame", GameMode: "ranked", ClientVersion: "1.3.0"},
+			want:    Target{AliasId: "alias-default", Queue: "default-queue"},
+			matched: true,
+		},
+		{
+			name:    "No matching route",
+			req:     Request{Namespace: "othergame"},
+			matched: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := table.Resolve(tt.req)
+			assert.Equal(t, tt.matched, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestResolveNilTable(t *testing.T) {
+	var table *Table
+	_, ok := table.Resolve(Request{Namespace: "mygame"})
+	assert.False(t, ok)
+}
+
+func TestParseJSON(t *testing.T) {
+	table, err := Parse([]byte(`{"routes": [{"match": {"game_mode": "casual"}, "queue": "casual-queue"}]}`))
+	require.NoError(t, err)
+
+	got, ok := table.Resolve(Request{GameMode: "casual"})
+	assert.True(t, ok)
+	assert.Equal(t, "casual-queue", got.Queue)
+}
+
+func TestParseInvalid(t *testing.T) {
+	_, err := Parse([]byte(`{"routes": [{"match": {"game_mode": "casual"}}]}`))
+	assert.Error(t, err)
+
+	_, err = Parse([]byte(`{"routes": [{"match": {"game_mode": "[casual"}, "queue": "q"}]}`))
+	assert.Error(t, err)
+
+	_, err = Parse([]byte(`{"routes": [{"match": {"game_mode": "casual"}, "location_priority": "nearest"}]}`))
+	assert.Error(t, err)
+}
+
+func TestParseLocationPriority(t *testing.T) {
+	table, err := Parse([]byte(`{"routes": [{"match": {"game_mode": "ranked"}, "location_priority": "requested_only"}]}`))
+	require.NoError(t, err)
+
+	got, ok := table.Resolve(Request{GameMode: "ranked"})
+	assert.True(t, ok)
+	assert.Equal(t, "requested_only", got.LocationPriority)
+}
+
+func TestSplitNeverDiscoversCanaryFleets(t *testing.T) {
+	target := Target{AliasId: "alias-stable", DiscoverFleet: true, Canary: &Canary{AliasId: "alias-canary", Percent: 100}}
+
+	got, variant := target.Split("session-1", PathSync)
+	assert.Equal(t, VariantCanary, variant)
+	assert.Equal(t, "alias-canary", got.AliasId)
+	assert.False(t, got.DiscoverFleet)
+
+	target.Canary.Percent = 0
+	got, variant = target.Split("session-1", PathSync)
+	assert.Equal(t, VariantStable, variant)
+	assert.True(t, got.DiscoverFleet)
+}
+
+func TestSplitKeepsStableWithoutCanaryTargetForPath(t *testing.T) {
+	target := Target{AliasId: "alias-stable", Queue: "stable-queue", Canary: &Canary{Queue: "canary-queue", Percent: 100}}
+
+	got, variant := target.Split("session-1", PathSync)
+	assert.Equal(t, VariantStable, variant)
+	assert.Equal(t, "alias-stable", got.AliasId)
+
+	got, variant = target.Split("session-1", PathAsync)
+	assert.Equal(t, VariantCanary, variant)
+	assert.Equal(t, "canary-queue", got.Queue)
+
+	target.Canary = &Canary{AliasId: "alias-canary", Percent: 100}
+
+	got, variant = target.Split("session-1", PathAsync)
+	assert.Equal(t, VariantStable, variant)
+	assert.Equal(t, "stable-queue", got.Queue)
+
+	got, variant = target.Split("session-1", PathSync)
+	assert.Equal(t, VariantCanary, variant)
+	assert.Equal(t, "alias-canary", got.AliasId)
+}
+
+func TestSplit(t *testing.T) {
+	table, err := Parse([]byte(`
+routes:
+  - match:
+      namespace: mygame
+    alias_id: alias-stable
+    queue: stable-queue
+    canary:
+      alias_id: alias-canary
+      percent: 20
+`))
+	require.NoError(t, err)
+
+	route, ok := table.Resolve(Request{Namespace: "mygame"})
+	require.True(t, ok)
+
+	canarySessions := 0
+	for i := 0; i < 1000; i++ {
+		sessionId := fmt.Sprintf("session-%d", i)
+		target, variant := route.Split(sessionId, PathSync)
+		assert.Nil(t, target.Canary)
+		assert.Equal(t, "stable-queue", target.Queue)
+
+		// Sessions stay on the same side every time they are split
+		again, againVariant := route.Split(sessionId, PathSync)
+		assert.Equal(t, variant, againVariant)
+		assert.Equal(t, target, again)
+
+		if variant == VariantCanary {
+			assert.Equal(t, "alias-canary", target.AliasId)
+			canarySessions++
+		} else {
+			assert.Equal(t, "alias-stable", target.AliasId)
+		}
+	}
+	assert.InDelta(t, 200, canarySessions, 50)
+
+	_, err = Parse([]byte(`{"routes": [{"queue": "q", "canary": {"percent": 10}}]}`))
+	assert.Error(t, err)
+
+	_, err = Parse([]byte(`{"routes": [{"queue": "q", "canary": {"queue": "c", "percent": 110}}]}`))
+	assert.Error(t, err)
+}
+
+func TestStoreReload(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "routing.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte(`{"routes": [{"queue": "first"}]}`), 0o600))
+
+	store, err := NewStore(filePath)
+	require.NoError(t, err)
+	target, _ := store.Table().Resolve(Request{})
+	assert.Equal(t, "first", target.Queue)
+
+	// An invalid file keeps the previous table
+	require.NoError(t, os.WriteFile(filePath, []byte(`{"routes": [{}]}`), 0o600))
+	assert.Error(t, store.reload())
+	target, _ = store.Table().Resolve(Request{})
+	assert.Equal(t, "first", target.Queue)
+
+	require.NoError(t, os.WriteFile(filePath, []byte(`{"routes": [{"queue": "second"}]}`), 0o600))
+	require.NoError(t, store.reload())
+	target, _ = store.Table().Resolve(Request{})
+	assert.Equal(t, "second", target.Queue)
+}
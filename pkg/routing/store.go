@@ -0,0 +1,101 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package routing
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Store holds the routing table loaded from a file, and reloads it when the file changes
+// This allows routes and canary percentages to be adjusted without a restart,
+// e.g. by updating the ConfigMap the file is mounted from
+type Store struct {
+	filePath string
+
+	mu      sync.RWMutex
+	table   *Table
+	content []byte
+}
+
+// NewStore loads the routing table from a YAML or JSON file
+func NewStore(filePath string) (*Store, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read routing table %s: %w", filePath, err)
+	}
+
+	table, err := Parse(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{filePath: filePath, table: table, content: content}, nil
+}
+
+// Table returns the current routing table
+func (s *Store) Table() *Table {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.table
+}
+
+// Watch checks the file for changes on the interval until ctx is done
+// A file that can't be read or parsed is logged, and the previous table is kept
+func (s *Store) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.reload(); err != nil {
+			logrus.Errorf("Failed to reload routing table %s, keeping the previous one: %v", s.filePath, err)
+		}
+	}
+}
+
+func (s *Store) reload() error {
+	content, err := os.ReadFile(s.filePath)
+	if err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	unchanged := bytes.Equal(content, s.content)
+	s.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	table, err := Parse(content)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.table = table
+	s.content = content
+	s.mu.Unlock()
+
+	logrus.Infof("Reloaded routing table %s", s.filePath)
+
+	return nil
+}
@@ -0,0 +1,218 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/gamelift"
+	"github.com/aws/aws-sdk-go-v2/service/gamelift/types"
+	"github.com/sirupsen/logrus"
+)
+
+// extraSessionTerminateTimeout bounds the cleanup call for sessions that are created but not handed to AGS
+const extraSessionTerminateTimeout = 10 * time.Second
+
+// DefaultAttemptTimeout is the time budget of a request that AGS sent without a deadline
+const DefaultAttemptTimeout = time.Minute
+
+// AttemptStrategy controls how CreateGameSession spreads its attempts across the requested regions
+type AttemptStrategy struct {
+	// HedgeDelay starts the next region while the current attempt is still running once this much time has passed
+	// Zero disables hedging, so regions are tried one after another
+	HedgeDelay time.Duration
+
+	// MinAttemptTimeout is the smallest share of the request deadline a single region attempt is given
+	MinAttemptTimeout time.Duration
+
+	// DefaultTimeout is the deadline of requests that have none, so attempts never outlive a cancelled request unbounded
+	// Zero uses DefaultAttemptTimeout
+	DefaultTimeout time.Duration
+}
+
+// regionAttemptFunc creates a game session in a single region
+// A game session returned with an error was created, but was not ready before the attempt ran out of time
+type regionAttemptFunc func(ctx context.Context, region string) (*types.GameSession, error)
+
+type regionAttemptResult struct {
+	region      string
+	gameSession *types.GameSession
+	err         error
+}
+
+// createInRegions tries the regions in order and returns the first game session that is created successfully
+// The remaining request deadline is split evenly between the regions that have not been tried yet
+// Game sessions created after the request ran out of time are recorded for sessionId, so the retry from AGS gets them back
+func (s *SessionDSM) createInRegions(
+	ctx context.Context,
+	log *logrus.Entry,
+	sessionId string,
+	regions []string,
+	attempt regionAttemptFunc,
+) (*types.GameSession, error) {
+	if len(regions) == 0 {
+		return nil, errors.New("no regions to attempt")
+	}
+
+	// Attempts don't stop when the request is cancelled, so they need a deadline to split even when AGS sent none
+	if _, ok := ctx.Deadline(); !ok {
+		timeout := s.AttemptStrategy.DefaultTimeout
+		if timeout <= 0 {
+			timeout = DefaultAttemptTimeout
+		}
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// Attempts outlive the request once a winner is found, so any session they still create can be cleaned up
+	attemptBaseCtx := context.WithoutCancel(ctx)
+	results := make(chan regionAttemptResult, len(regions))
+	launched := 0
+	inFlight := 0
+
+	launch := func() {
+		region := regions[launched]
+		budget := s.attemptBudget(ctx, len(regions)-launched)
+		launched++
+		inFlight++
+
+		log.Debugf("Attempting to create Game Session in region %s with budget %v", region, budget)
+		go func() {
+			attemptCtx, cancel := context.WithTimeout(attemptBaseCtx, budget)
+			defer cancel()
+
+			gameSession, err := attempt(attemptCtx, region)
+			results <- regionAttemptResult{region: region, gameSession: gameSession, err: err}
+		}()
+	}
+
+	launch()
+
+	var lastErr error
+	for inFlight > 0 {
+		var hedge <-chan time.Time
+		if s.AttemptStrategy.HedgeDelay > 0 && launched < len(regions) {
+			hedge = time.After(s.AttemptStrategy.HedgeDelay)
+		}
+
+		select {
+		case result := <-results:
+			inFlight--
+			if result.err != nil {
+				log.Warnf("Failed to create Game Session in region %s: %s", result.region, result.err)
+				lastErr = result.err
+
+				if result.gameSession != nil && result.gameSession.GameSessionId != nil {
+					// Once the request is out of time or regions, nothing else is created for it, so the session is kept
+					if ctx.Err() != nil || (launched == len(regions) && inFlight == 0) {
+						s.keepLateGameSession(sessionId, result, log)
+						go s.settleLateGameSessions(sessionId, results, inFlight, true, log)
+
+						return nil, lastErr
+					}
+
+					// The next region is attempted, so this session would only be a duplicate
+					go s.discardGameSession(result.gameSession.GameSessionId, log)
+				}
+
+				if launched < len(regions) && ctx.Err() == nil {
+					launch()
+				}
+
+				continue
+			}
+
+			if inFlight > 0 {
+				go s.terminateExtraGameSessions(results, inFlight, log)
+			}
+
+			return result.gameSession, nil
+		case <-hedge:
+			log.Debugf("Region %s has not responded after %v, hedging with the next region", regions[launched-1], s.AttemptStrategy.HedgeDelay)
+			launch()
+		case <-ctx.Done():
+			go s.settleLateGameSessions(sessionId, results, inFlight, false, log)
+
+			return nil, ctx.Err()
+		}
+	}
+
+	return nil, lastErr
+}
+
+// attemptBudget returns the share of the remaining deadline for the next attempt
+func (s *SessionDSM) attemptBudget(ctx context.Context, regionsLeft int) time.Duration {
+	deadline, _ := ctx.Deadline()
+
+	remaining := time.Until(deadline)
+	budget := remaining / time.Duration(regionsLeft)
+	if budget < s.AttemptStrategy.MinAttemptTimeout {
+		budget = s.AttemptStrategy.MinAttemptTimeout
+	}
+	if budget > remaining {
+		budget = remaining
+	}
+
+	return budget
+}
+
+// terminateExtraGameSessions waits for the attempts still in flight and terminates any session they create
+func (s *SessionDSM) terminateExtraGameSessions(results <-chan regionAttemptResult, inFlight int, log *logrus.Entry) {
+	for ; inFlight > 0; inFlight-- {
+		result := <-results
+		if result.gameSession == nil || result.gameSession.GameSessionId == nil {
+			continue
+		}
+
+		log.Infof("Terminating extra Game Session %s created in region %s", *result.gameSession.GameSessionId, result.region)
+		s.discardGameSession(result.gameSession.GameSessionId, log)
+	}
+}
+
+// settleLateGameSessions waits for the attempts still in flight after the request ran out of time
+// A session created this late reached GameLift, so terminating it would leave its region unusable for the retry from AGS:
+// the idempotency token of the region would keep returning the terminated session. The first one is kept for the retry
+// instead, and only the others are terminated
+func (s *SessionDSM) settleLateGameSessions(sessionId string, results <-chan regionAttemptResult, inFlight int, kept bool, log *logrus.Entry) {
+	for ; inFlight > 0; inFlight-- {
+		result := <-results
+		if result.gameSession == nil || result.gameSession.GameSessionId == nil {
+			continue
+		}
+
+		if !kept {
+			s.keepLateGameSession(sessionId, result, log)
+			kept = true
+			continue
+		}
+
+		log.Infof("Terminating extra Game Session %s created in region %s", *result.gameSession.GameSessionId, result.region)
+		s.discardGameSession(result.gameSession.GameSessionId, log)
+	}
+}
+
+// keepLateGameSession records a game session created after the request ran out of time, so the retry from AGS returns it
+func (s *SessionDSM) keepLateGameSession(sessionId string, result regionAttemptResult, log *logrus.Entry) {
+	log.Infof("Keeping Game Session %s created in region %s after the request ran out of time", *result.gameSession.GameSessionId, result.region)
+	s.GameSessionRecords.Put(sessionId, *result.gameSession.GameSessionId)
+}
+
+// discardGameSession force terminates a game session that was created but will not be handed to AGS
+func (s *SessionDSM) discardGameSession(gameSessionId *string, log *logrus.Entry) {
+	ctx, cancel := context.WithTimeout(context.Background(), extraSessionTerminateTimeout)
+	defer cancel()
+
+	_, err := s.GameLiftClient.TerminateGameSession(ctx, &gamelift.TerminateGameSessionInput{
+		GameSessionId:   gameSessionId,
+		TerminationMode: types.TerminationModeForceTerminate, // No players have been sent to this session
+	})
+	if err != nil {
+		log.Errorf("Failed to terminate discarded Game Session %s: %v", *gameSessionId, err)
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/gamelift"
+	"github.com/aws/aws-sdk-go-v2/service/gamelift/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTerminateClient records the game sessions terminated as extra or discarded sessions
+type fakeTerminateClient struct {
+	AmazonGameLiftClient
+
+	mu         sync.Mutex
+	terminated []string
+}
+
+func (c *fakeTerminateClient) TerminateGameSession(_ context.Context, input *gamelift.TerminateGameSessionInput, _ ...func(*gamelift.Options)) (*gamelift.TerminateGameSessionOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.terminated = append(c.terminated, *input.GameSessionId)
+
+	return &gamelift.TerminateGameSessionOutput{}, nil
+}
+
+func (c *fakeTerminateClient) terminatedSessions() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]string(nil), c.terminated...)
+}
+
+func gameSessionIn(region string) *types.GameSession {
+	return &types.GameSession{
+		GameSessionId: aws.String("arn:aws:gamelift:" + region + "::gamesession/fleet-1234/" + region),
+		Location:      aws.String(region),
+		Status:        types.GameSessionStatusActivating,
+	}
+}
+
+func TestCreateInRegionsKeepsLateGameSession(t *testing.T) {
+	gameLift := &fakeTerminateClient{}
+	s := &SessionDSM{
+		GameLiftClient:     gameLift,
+		GameSessionRecords: NewGameSessionRecords(time.Hour),
+		AttemptStrategy:    AttemptStrategy{HedgeDelay: 10 * time.Millisecond},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// Both regions only answer after the request ran out of time
+	_, err := s.createInRegions(ctx, logrus.NewEntry(logrus.New()), "session-1", []string{"us-west-2", "us-east-1"}, func(_ context.Context, region string) (*types.GameSession, error) {
+		delay := 100 * time.Millisecond
+		if region == "us-east-1" {
+			delay = 150 * time.Millisecond
+		}
+		time.Sleep(delay)
+
+		return gameSessionIn(region), nil
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The first late session is kept for the retry from AGS, and only the second one is terminated
+	require.Eventually(t, func() bool { return len(gameLift.terminatedSessions()) == 1 }, time.Second, 10*time.Millisecond)
+	gameSessionId, ok := s.GameSessionRecords.Get("session-1")
+	assert.True(t, ok)
+	assert.Equal(t, *gameSessionIn("us-west-2").GameSessionId, gameSessionId)
+	assert.Equal(t, []string{*gameSessionIn("us-east-1").GameSessionId}, gameLift.terminatedSessions())
+}
+
+func TestCreateInRegionsKeepsGameSessionThatDidNotActivateInTime(t *testing.T) {
+	gameLift := &fakeTerminateClient{}
+	s := &SessionDSM{
+		GameLiftClient:     gameLift,
+		GameSessionRecords: NewGameSessionRecords(time.Hour),
+	}
+
+	_, err := s.createInRegions(context.Background(), logrus.NewEntry(logrus.New()), "session-1", []string{"us-west-2"}, func(_ context.Context, region string) (*types.GameSession, error) {
+		return gameSessionIn(region), context.DeadlineExceeded
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	gameSessionId, ok := s.GameSessionRecords.Get("session-1")
+	assert.True(t, ok)
+	assert.Equal(t, *gameSessionIn("us-west-2").GameSessionId, gameSessionId)
+	assert.Empty(t, gameLift.terminatedSessions())
+}
+
+func TestCreateInRegionsFallsBackToNextRegion(t *testing.T) {
+	gameLift := &fakeTerminateClient{}
+	s := &SessionDSM{GameLiftClient: gameLift}
+
+	var mu sync.Mutex
+	var attempted []string
+	gameSession, err := s.createInRegions(context.Background(), logrus.NewEntry(logrus.New()), "session-1", []string{"us-west-2", "us-east-1", "eu-west-1"}, func(_ context.Context, region string) (*types.GameSession, error) {
+		mu.Lock()
+		attempted = append(attempted, region)
+		mu.Unlock()
+
+		if region == "us-west-2" {
+			return nil, errors.New("no available process")
+		}
+
+		return gameSessionIn(region), nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "us-east-1", *gameSession.Location)
+	assert.Equal(t, []string{"us-west-2", "us-east-1"}, attempted)
+	assert.Empty(t, gameLift.terminatedSessions())
+}
+
+func TestCreateInRegionsReturnsLastError(t *testing.T) {
+	s := &SessionDSM{GameLiftClient: &fakeTerminateClient{}}
+
+	_, err := s.createInRegions(context.Background(), logrus.NewEntry(logrus.New()), "session-1", []string{"us-west-2", "us-east-1"}, func(_ context.Context, region string) (*types.GameSession, error) {
+		return nil, errors.New("no available process in " + region)
+	})
+	assert.EqualError(t, err, "no available process in us-east-1")
+
+	_, err = s.createInRegions(context.Background(), logrus.NewEntry(logrus.New()), "session-1", nil, nil)
+	assert.Error(t, err)
+}
+
+func TestCreateInRegionsHedgesAndTerminatesExtraGameSession(t *testing.T) {
+	gameLift := &fakeTerminateClient{}
+	s := &SessionDSM{
+		GameLiftClient:  gameLift,
+		AttemptStrategy: AttemptStrategy{HedgeDelay: 10 * time.Millisecond},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// The first region is slow, so the hedge in the second region wins and the slow session is terminated once it is created
+	gameSession, err := s.createInRegions(ctx, logrus.NewEntry(logrus.New()), "session-1", []string{"us-west-2", "us-east-1"}, func(_ context.Context, region string) (*types.GameSession, error) {
+		if region == "us-west-2" {
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		return gameSessionIn(region), nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "us-east-1", *gameSession.Location)
+	require.Eventually(t, func() bool { return len(gameLift.terminatedSessions()) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{*gameSessionIn("us-west-2").GameSessionId}, gameLift.terminatedSessions())
+}
+
+func TestCreateInRegionsSplitsDeadline(t *testing.T) {
+	s := &SessionDSM{GameLiftClient: &fakeTerminateClient{}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var budget time.Duration
+	_, err := s.createInRegions(ctx, logrus.NewEntry(logrus.New()), "session-1", []string{"us-west-2", "us-east-1", "eu-west-1"}, func(attemptCtx context.Context, region string) (*types.GameSession, error) {
+		deadline, ok := attemptCtx.Deadline()
+		require.True(t, ok)
+		budget = time.Until(deadline)
+
+		return gameSessionIn(region), nil
+	})
+	require.NoError(t, err)
+
+	// The first of three regions gets a third of the request deadline
+	assert.InDelta(t, time.Second, budget, float64(100*time.Millisecond))
+}
+
+func TestCreateInRegionsWithoutDeadlineUsesDefaultTimeout(t *testing.T) {
+	s := &SessionDSM{
+		GameLiftClient:  &fakeTerminateClient{},
+		AttemptStrategy: AttemptStrategy{DefaultTimeout: 2 * time.Second},
+	}
+
+	var budget time.Duration
+	_, err := s.createInRegions(context.Background(), logrus.NewEntry(logrus.New()), "session-1", []string{"us-west-2", "us-east-1"}, func(attemptCtx context.Context, region string) (*types.GameSession, error) {
+		deadline, ok := attemptCtx.Deadline()
+		require.True(t, ok)
+		budget = time.Until(deadline)
+
+		return gameSessionIn(region), nil
+	})
+	require.NoError(t, err)
+
+	assert.InDelta(t, time.Second, budget, float64(100*time.Millisecond))
+}
+
+func TestAttemptBudget(t *testing.T) {
+	s := &SessionDSM{AttemptStrategy: AttemptStrategy{MinAttemptTimeout: 500 * time.Millisecond}}
+
+	tests := []struct {
+		name        string
+		remaining   time.Duration
+		regionsLeft int
+		expected    time.Duration
+	}{
+		{name: "split evenly", remaining: 3 * time.Second, regionsLeft: 3, expected: time.Second},
+		{name: "last region gets the rest", remaining: 3 * time.Second, regionsLeft: 1, expected: 3 * time.Second},
+		{name: "at least the minimum", remaining: time.Second, regionsLeft: 4, expected: 500 * time.Millisecond},
+		{name: "never more than remaining", remaining: 200 * time.Millisecond, regionsLeft: 2, expected: 200 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), tt.remaining)
+			defer cancel()
+
+			assert.InDelta(t, tt.expected, s.attemptBudget(ctx, tt.regionsLeft), float64(50*time.Millisecond))
+		})
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/gamelift"
+	"github.com/sirupsen/logrus"
+)
+
+// CapacityPrecheckMode decides what happens to locations that have no idle game server processes
+type CapacityPrecheckMode string
+
+const (
+	CapacityPrecheckOff          CapacityPrecheckMode = ""
+	CapacityPrecheckSkip         CapacityPrecheckMode = "skip"
+	CapacityPrecheckDeprioritize CapacityPrecheckMode = "deprioritize"
+)
+
+func parseCapacityPrecheckMode(value string) (CapacityPrecheckMode, error) {
+	mode := CapacityPrecheckMode(strings.ToLower(value))
+	switch mode {
+	case CapacityPrecheckOff, CapacityPrecheckSkip, CapacityPrecheckDeprioritize:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown capacity precheck mode %q, expected skip or deprioritize", value)
+	}
+}
+
+type deploymentLocation struct {
+	deployment string
+	location   string
+}
+
+type fleetLocation struct {
+	fleetId  string
+	location string
+}
+
+// CapacityCache keeps the idle game server process count of the fleet locations CreateGameSession uses
+// Targets are tracked the first time they are requested and refreshed in the background, so the request path never
+// waits on GameLift. Locations that have not been refreshed yet are treated as unknown
+// Aliases are resolved to their fleet on every refresh, since they can be pointed at a new fleet at any time
+// Targets that have not been requested for trackingTtl are dropped, so retired deployments stop being refreshed
+type CapacityCache struct {
+	client      AmazonGameLiftClient
+	mode        CapacityPrecheckMode
+	interval    time.Duration
+	trackingTtl time.Duration
+
+	mu               sync.RWMutex
+	tracked          map[deploymentLocation]time.Time
+	deploymentFleets map[string]string
+	idleProcesses    map[fleetLocation]int32
+
+	refresh chan struct{}
+}
+
+func NewCapacityCache(client AmazonGameLiftClient, mode CapacityPrecheckMode, interval, trackingTtl time.Duration) *CapacityCache {
+	return &CapacityCache{
+		client:           client,
+		mode:             mode,
+		interval:         interval,
+		trackingTtl:      trackingTtl,
+		tracked:          make(map[deploymentLocation]time.Time),
+		deploymentFleets: make(map[string]string),
+		idleProcesses:    make(map[fleetLocation]int32),
+		refresh:          make(chan struct{}, 1),
+	}
+}
+
+// Run refreshes the cache on the configured interval until ctx is done
+func (c *CapacityCache) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-c.refresh:
+		}
+
+		c.refreshAll(ctx)
+	}
+}
+
+// Order applies the precheck mode to the regions of a request
+// In skip mode, locations known to have no idle processes are removed. In deprioritize mode they are moved to the back
+func (c *CapacityCache) Order(deployment string, regions []string) []string {
+	if c == nil || c.mode == CapacityPrecheckOff {
+		return regions
+	}
+
+	var available, exhausted []string
+	for _, region := range regions {
+		idle, known := c.idleProcessCount(deploymentLocation{deployment: deployment, location: region})
+		if known && idle <= 0 {
+			exhausted = append(exhausted, region)
+			continue
+		}
+		available = append(available, region)
+	}
+
+	if c.mode == CapacityPrecheckSkip {
+		return available
+	}
+
+	return append(available, exhausted...)
+}
+
+func (c *CapacityCache) idleProcessCount(target deploymentLocation) (int32, bool) {
+	c.mu.Lock()
+	_, tracked := c.tracked[target]
+	c.tracked[target] = time.Now()
+	fleetId, resolved := c.deploymentFleets[target.deployment]
+	idle, known := c.idleProcesses[fleetLocation{fleetId: fleetId, location: target.location}]
+	c.mu.Unlock()
+
+	if !tracked {
+		// Ask for an early refresh so the new target doesn't wait for the next tick
+		select {
+		case c.refresh <- struct{}{}:
+		default:
+		}
+	}
+
+	return idle, resolved && known
+}
+
+func (c *CapacityCache) refreshAll(ctx context.Context) {
+	c.mu.Lock()
+	targets := make([]deploymentLocation, 0, len(c.tracked))
+	for target, lastRequested := range c.tracked {
+		if time.Since(lastRequested) > c.trackingTtl {
+			delete(c.tracked, target)
+			continue
+		}
+		targets = append(targets, target)
+	}
+	c.mu.Unlock()
+
+	deploymentFleets := make(map[string]string)
+	idleProcesses := make(map[fleetLocation]int32)
+	for _, target := range targets {
+		fleetId, ok := deploymentFleets[target.deployment]
+		if !ok {
+			var err error
+			fleetId, err = resolveFleetId(ctx, c.client, parseDeployment(target.deployment))
+			if err != nil {
+				logrus.Warnf("Failed to resolve the fleet of %s for capacity precheck: %v", target.deployment, err)
+				continue
+			}
+			deploymentFleets[target.deployment] = fleetId
+		}
+
+		key := fleetLocation{fleetId: fleetId, location: target.location}
+		if _, ok := idleProcesses[key]; ok {
+			continue
+		}
+
+		utilizationResponse, err := c.client.DescribeFleetLocationUtilization(ctx, &gamelift.DescribeFleetLocationUtilizationInput{
+			FleetId:  &key.fleetId,
+			Location: &key.location,
+		})
+		if err != nil || utilizationResponse.FleetUtilization == nil {
+			logrus.Warnf("Failed to describe utilization of fleet %s in %s for capacity precheck: %v", key.fleetId, key.location, err)
+			continue
+		}
+
+		utilization := utilizationResponse.FleetUtilization
+		var activeProcesses, activeGameSessions int32
+		if utilization.ActiveServerProcessCount != nil {
+			activeProcesses = *utilization.ActiveServerProcessCount
+		}
+		if utilization.ActiveGameSessionCount != nil {
+			activeGameSessions = *utilization.ActiveGameSessionCount
+		}
+		idleProcesses[key] = activeProcesses - activeGameSessions
+	}
+
+	c.mu.Lock()
+	c.deploymentFleets = deploymentFleets
+	c.idleProcesses = idleProcesses
+	c.mu.Unlock()
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/gamelift"
+	"github.com/aws/aws-sdk-go-v2/service/gamelift/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	capacityAliasId = "alias-11111111-1111-1111-1111-111111111111"
+	capacityFleetId = "fleet-22222222-2222-2222-2222-222222222222"
+)
+
+// fakeUtilizationClient reports the idle processes of capacityFleetId by location, and routes capacityAliasId to it
+type fakeUtilizationClient struct {
+	AmazonGameLiftClient
+
+	idle      map[string]int32
+	described []string
+}
+
+func (c *fakeUtilizationClient) ResolveAlias(_ context.Context, input *gamelift.ResolveAliasInput, _ ...func(*gamelift.Options)) (*gamelift.ResolveAliasOutput, error) {
+	if *input.AliasId != capacityAliasId {
+		return nil, errors.New("alias not found")
+	}
+
+	return &gamelift.ResolveAliasOutput{FleetId: aws.String(capacityFleetId)}, nil
+}
+
+func (c *fakeUtilizationClient) DescribeFleetLocationUtilization(_ context.Context, input *gamelift.DescribeFleetLocationUtilizationInput, _ ...func(*gamelift.Options)) (*gamelift.DescribeFleetLocationUtilizationOutput, error) {
+	c.described = append(c.described, *input.Location)
+
+	idle, ok := c.idle[*input.Location]
+	if !ok {
+		return nil, errors.New("location not found")
+	}
+
+	return &gamelift.DescribeFleetLocationUtilizationOutput{FleetUtilization: &types.FleetUtilization{
+		ActiveServerProcessCount: aws.Int32(idle + 2),
+		ActiveGameSessionCount:   aws.Int32(2),
+	}}, nil
+}
+
+func TestCapacityCacheOrder(t *testing.T) {
+	regions := []string{"us-west-2", "us-east-1", "eu-west-1", "ap-south-1"}
+
+	tests := []struct {
+		name       string
+		mode       CapacityPrecheckMode
+		deployment string
+		expected   []string
+	}{
+		{name: "skip", mode: CapacityPrecheckSkip, deployment: capacityAliasId, expected: []string{"us-east-1", "ap-south-1"}},
+		{name: "deprioritize", mode: CapacityPrecheckDeprioritize, deployment: capacityAliasId, expected: []string{"us-east-1", "ap-south-1", "us-west-2", "eu-west-1"}},
+		{name: "unresolved deployment", mode: CapacityPrecheckSkip, deployment: "alias-33333333-3333-3333-3333-333333333333", expected: regions},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ap-south-1 fails to describe, so its capacity stays unknown and it is attempted as usual
+			client := &fakeUtilizationClient{idle: map[string]int32{"us-west-2": 0, "us-east-1": 3, "eu-west-1": -1}}
+			cache := NewCapacityCache(client, tt.mode, time.Minute, time.Hour)
+
+			// Nothing is known before the first refresh
+			assert.Equal(t, regions, cache.Order(tt.deployment, regions))
+
+			cache.refreshAll(context.Background())
+			assert.Equal(t, tt.expected, cache.Order(tt.deployment, regions))
+		})
+	}
+
+	var cache *CapacityCache
+	assert.Equal(t, regions, cache.Order(capacityAliasId, regions))
+}
+
+func TestCapacityCacheRefreshAll(t *testing.T) {
+	client := &fakeUtilizationClient{idle: map[string]int32{"us-west-2": 0, "us-east-1": 3}}
+	cache := NewCapacityCache(client, CapacityPrecheckSkip, time.Minute, time.Hour)
+
+	cache.Order(capacityAliasId, []string{"us-west-2", "us-east-1"})
+	cache.Order(capacityFleetId, []string{"us-west-2"})
+	cache.refreshAll(context.Background())
+
+	// The alias and the fleet it routes to share the utilization of each location
+	assert.ElementsMatch(t, []string{"us-west-2", "us-east-1"}, client.described)
+	idle, known := cache.idleProcessCount(deploymentLocation{deployment: capacityAliasId, location: "us-east-1"})
+	assert.True(t, known)
+	assert.Equal(t, int32(3), idle)
+
+	// A location that can no longer be described is unknown after the next refresh instead of keeping stale data
+	delete(client.idle, "us-east-1")
+	cache.refreshAll(context.Background())
+	_, known = cache.idleProcessCount(deploymentLocation{deployment: capacityAliasId, location: "us-east-1"})
+	assert.False(t, known)
+}
+
+func TestCapacityCacheExpiresIdleTargets(t *testing.T) {
+	client := &fakeUtilizationClient{idle: map[string]int32{"us-west-2": 0, "us-east-1": 0}}
+	cache := NewCapacityCache(client, CapacityPrecheckSkip, time.Minute, time.Hour)
+
+	cache.Order(capacityAliasId, []string{"us-west-2", "us-east-1"})
+	cache.mu.Lock()
+	cache.tracked[deploymentLocation{deployment: capacityAliasId, location: "us-east-1"}] = time.Now().Add(-2 * time.Hour)
+	cache.mu.Unlock()
+
+	cache.refreshAll(context.Background())
+	assert.Equal(t, []string{"us-west-2"}, client.described)
+	assert.NotContains(t, cache.tracked, deploymentLocation{deployment: capacityAliasId, location: "us-east-1"})
+
+	// Requesting the location again tracks it again
+	assert.Equal(t, []string{"us-east-1"}, cache.Order(capacityAliasId, []string{"us-west-2", "us-east-1"}))
+	assert.Contains(t, cache.tracked, deploymentLocation{deployment: capacityAliasId, location: "us-east-1"})
+}
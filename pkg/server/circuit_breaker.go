@@ -0,0 +1,140 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	breakerTargetAlias = string(deploymentAlias)
+	breakerTargetQueue = string(deploymentQueue)
+)
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// CircuitBreakerConfig controls when a GameLift target is skipped after repeated failures
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that opens the circuit. Zero disables the breakers
+	FailureThreshold int
+
+	// Cooldown is how long an open circuit skips its target before a single probe request is let through
+	Cooldown time.Duration
+}
+
+// breakerKey identifies a GameLift target. Location is empty for queues
+type breakerKey struct {
+	targetType string
+	target     string
+	location   string
+}
+
+type circuitBreaker struct {
+	state               circuitState
+	consecutiveFailures int
+	openedAt            time.Time
+	probeInFlight       bool
+}
+
+// CircuitBreakers tracks one circuit breaker per (alias, location) and per queue
+type CircuitBreakers struct {
+	config CircuitBreakerConfig
+
+	mu       sync.Mutex
+	breakers map[breakerKey]*circuitBreaker
+	now      func() time.Time
+}
+
+func NewCircuitBreakers(config CircuitBreakerConfig) *CircuitBreakers {
+	return &CircuitBreakers{
+		config:   config,
+		breakers: make(map[breakerKey]*circuitBreaker),
+		now:      time.Now,
+	}
+}
+
+// errCircuitOpen is returned in place of calling a target whose circuit is open
+var errCircuitOpen = newStatusError(codes.Unavailable, errorDomainSessionDSM, "CIRCUIT_OPEN", "", true, "circuit breaker is open for this GameLift target")
+
+// Allow reports whether a request may be sent to the target
+// Once the cooldown of an open circuit has passed, exactly one probe is allowed until its result is recorded
+func (c *CircuitBreakers) Allow(key breakerKey) bool {
+	if c == nil || c.config.FailureThreshold <= 0 {
+		return true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	breaker, ok := c.breakers[key]
+	if !ok {
+		return true
+	}
+
+	switch breaker.state {
+	case circuitOpen:
+		if c.now().Sub(breaker.openedAt) < c.config.Cooldown {
+			return false
+		}
+		c.setState(key, breaker, circuitHalfOpen)
+		breaker.probeInFlight = true
+
+		return true
+	case circuitHalfOpen:
+		if breaker.probeInFlight {
+			return false
+		}
+		breaker.probeInFlight = true
+
+		return true
+	default:
+		return true
+	}
+}
+
+// Record stores the outcome of a request that Allow let through
+// Only transient failures count towards opening the circuit, since a bad request says nothing about the target
+func (c *CircuitBreakers) Record(key breakerKey, err error) {
+	if c == nil || c.config.FailureThreshold <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	breaker, ok := c.breakers[key]
+	if !ok {
+		breaker = &circuitBreaker{}
+		c.breakers[key] = breaker
+	}
+	breaker.probeInFlight = false
+
+	if err == nil || !isTransientGameLiftError(err) {
+		breaker.consecutiveFailures = 0
+		c.setState(key, breaker, circuitClosed)
+
+		return
+	}
+
+	breaker.consecutiveFailures++
+	if breaker.state == circuitHalfOpen || breaker.consecutiveFailures >= c.config.FailureThreshold {
+		breaker.openedAt = c.now()
+		c.setState(key, breaker, circuitOpen)
+	}
+}
+
+func (c *CircuitBreakers) setState(key breakerKey, breaker *circuitBreaker, state circuitState) {
+	breaker.state = state
+	circuitBreakerState.WithLabelValues(key.targetType, key.target, key.location).Set(float64(state))
+}
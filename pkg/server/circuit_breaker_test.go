@@ -0,0 +1,62 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/gamelift/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreakers(t *testing.T) {
+	now := time.Now()
+	breakers := NewCircuitBreakers(CircuitBreakerConfig{FailureThreshold: 2, Cooldown: time.Minute})
+	breakers.now = func() time.Time { return now }
+
+	key := breakerKey{targetType: breakerTargetAlias, target: "alias-1", location: "us-west-2"}
+	capacityErr := &types.FleetCapacityExceededException{}
+
+	// Bad requests don't open the circuit
+	breakers.Record(key, &types.InvalidRequestException{})
+	breakers.Record(key, &types.InvalidRequestException{})
+	assert.True(t, breakers.Allow(key))
+
+	// Consecutive transient failures open it
+	breakers.Record(key, capacityErr)
+	assert.True(t, breakers.Allow(key))
+	breakers.Record(key, capacityErr)
+	assert.False(t, breakers.Allow(key))
+
+	// Other targets are unaffected
+	assert.True(t, breakers.Allow(breakerKey{targetType: breakerTargetAlias, target: "alias-1", location: "us-east-1"}))
+
+	// After the cooldown a single probe is let through
+	now = now.Add(time.Minute)
+	assert.True(t, breakers.Allow(key))
+	assert.False(t, breakers.Allow(key))
+
+	// A failed probe opens the circuit again
+	breakers.Record(key, capacityErr)
+	assert.False(t, breakers.Allow(key))
+
+	// A successful probe closes it
+	now = now.Add(time.Minute)
+	assert.True(t, breakers.Allow(key))
+	breakers.Record(key, nil)
+	assert.True(t, breakers.Allow(key))
+	assert.True(t, breakers.Allow(key))
+}
+
+func TestCircuitBreakersDisabled(t *testing.T) {
+	breakers := NewCircuitBreakers(CircuitBreakerConfig{})
+	key := breakerKey{targetType: breakerTargetQueue, target: "queue-1"}
+
+	for i := 0; i < 10; i++ {
+		breakers.Record(key, &types.FleetCapacityExceededException{})
+	}
+	assert.True(t, breakers.Allow(key))
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/gamelift"
+	"github.com/aws/aws-sdk-go-v2/service/gamelift/types"
+	"github.com/sirupsen/logrus"
+)
+
+// AddressMode decides which address of a game session is returned to AGS
+type AddressMode string
+
+const (
+	AddressIp AddressMode = "ip"
+
+	// AddressDns returns the DNS name, which clients need to complete TLS with fleets that generate certificates
+	AddressDns AddressMode = "dns"
+
+	// AddressAuto returns the DNS name for fleets whose CertificateConfiguration is GENERATED, and the IP otherwise
+	AddressAuto AddressMode = "auto"
+)
+
+func parseAddressMode(value string) (AddressMode, error) {
+	mode := AddressMode(strings.ToLower(value))
+	switch mode {
+	case "":
+		return AddressIp, nil
+	case AddressIp, AddressDns, AddressAuto:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown game session address %q, expected ip, dns or auto", value)
+	}
+}
+
+// TLSFleets remembers which fleets generate TLS certificates. The certificate configuration of a fleet can't change
+type TLSFleets struct {
+	mu     sync.RWMutex
+	fleets map[string]bool
+}
+
+func NewTLSFleets() *TLSFleets {
+	return &TLSFleets{fleets: make(map[string]bool)}
+}
+
+func (t *TLSFleets) get(fleetId string) (bool, bool) {
+	if t == nil {
+		return false, false
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	generated, ok := t.fleets[fleetId]
+
+	return generated, ok
+}
+
+func (t *TLSFleets) put(fleetId string, generated bool) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.fleets[fleetId] = generated
+}
+
+// isTLSFleet reports whether the fleet was created with a generated TLS certificate
+func (s *SessionDSM) isTLSFleet(ctx context.Context, fleetId string) (bool, error) {
+	if generated, ok := s.TLSFleets.get(fleetId); ok {
+		return generated, nil
+	}
+
+	attributesResponse, err := s.GameLiftClient.DescribeFleetAttributes(ctx, &gamelift.DescribeFleetAttributesInput{
+		FleetIds: []string{fleetId},
+	})
+	if err != nil {
+		return false, err
+	}
+	if len(attributesResponse.FleetAttributes) == 0 {
+		return false, fmt.Errorf("fleet %s was not found", fleetId)
+	}
+
+	certificate := attributesResponse.FleetAttributes[0].CertificateConfiguration
+	generated := certificate != nil && certificate.CertificateType == types.CertificateTypeGenerated
+	s.TLSFleets.put(fleetId, generated)
+
+	return generated, nil
+}
+
+// gameSessionAddress returns the address clients use to connect to the game session
+// The IP is used whenever the DNS name is not available, and an empty address is returned rather than panicking when neither is
+func (s *SessionDSM) gameSessionAddress(ctx context.Context, log *logrus.Entry, gameSession *types.GameSession, mode AddressMode) string {
+	var ipAddress, dnsName string
+	if gameSession.IpAddress != nil {
+		ipAddress = *gameSession.IpAddress
+	}
+	if gameSession.DnsName != nil {
+		dnsName = *gameSession.DnsName
+	}
+
+	useDns := mode == AddressDns
+	if mode == AddressAuto && gameSession.FleetId != nil {
+		generated, err := s.isTLSFleet(ctx, *gameSession.FleetId)
+		if err != nil {
+			log.Warnf("Failed to check the certificate configuration of fleet %s, returning the IP address: %v", *gameSession.FleetId, err)
+		}
+		useDns = generated
+	}
+
+	if useDns && dnsName != "" {
+		return dnsName
+	}
+
+	return ipAddress
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/gamelift/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGameSessionAddress(t *testing.T) {
+	client := &fakeFleetClient{
+		fleets: []types.FleetAttributes{
+			{FleetId: aws.String("fleet-tls"), CertificateConfiguration: &types.CertificateConfiguration{CertificateType: types.CertificateTypeGenerated}},
+			{FleetId: aws.String("fleet-plain"), CertificateConfiguration: &types.CertificateConfiguration{CertificateType: types.CertificateTypeDisabled}},
+		},
+	}
+	s := &SessionDSM{GameLiftClient: client, TLSFleets: NewTLSFleets()}
+	log := logrus.NewEntry(logrus.StandardLogger())
+
+	tlsSession := &types.GameSession{FleetId: aws.String("fleet-tls"), IpAddress: aws.String("10.0.0.1"), DnsName: aws.String("tls.example.com")}
+	plainSession := &types.GameSession{FleetId: aws.String("fleet-plain"), IpAddress: aws.String("10.0.0.2"), DnsName: aws.String("plain.example.com")}
+
+	assert.Equal(t, "10.0.0.1", s.gameSessionAddress(context.Background(), log, tlsSession, AddressIp))
+	assert.Equal(t, "tls.example.com", s.gameSessionAddress(context.Background(), log, tlsSession, AddressDns))
+	assert.Equal(t, "tls.example.com", s.gameSessionAddress(context.Background(), log, tlsSession, AddressAuto))
+	assert.Equal(t, "10.0.0.2", s.gameSessionAddress(context.Background(), log, plainSession, AddressAuto))
+
+	// Sessions without a DNS name or IP don't panic
+	assert.Equal(t, "10.0.0.3", s.gameSessionAddress(context.Background(), log, &types.GameSession{IpAddress: aws.String("10.0.0.3")}, AddressDns))
+	assert.Equal(t, "", s.gameSessionAddress(context.Background(), log, &types.GameSession{}, AddressAuto))
+}
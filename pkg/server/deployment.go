@@ -0,0 +1,116 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/gamelift"
+)
+
+type deploymentKind string
+
+const (
+	deploymentAlias deploymentKind = "alias"
+	deploymentFleet deploymentKind = "fleet"
+	deploymentQueue deploymentKind = "queue"
+)
+
+var gameLiftArnPattern = regexp.MustCompile(`^arn:aws[a-z-]*:gamelift:[^:]*:[^:]*:(alias|fleet|gamesessionqueue)/(.+)$`)
+
+// gameLiftIdPattern matches alias and fleet IDs, which GameLift generates as the resource type followed by a UUID
+// Queue names are chosen freely, so a name like fleet-queue-usw2 must not be taken for a fleet
+var gameLiftIdPattern = regexp.MustCompile(`^(alias|fleet)-[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// deployment is the GameLift resource named by the Deployment field of a request
+type deployment struct {
+	kind deploymentKind
+
+	// value is the Deployment as given, either a short identifier or a full ARN. GameLift accepts both
+	value string
+
+	// id is the short identifier, e.g. alias-1234 or the queue name
+	id string
+}
+
+// parseDeployment recognises alias IDs, alias ARNs, fleet IDs, fleet ARNs and queue names or ARNs
+// Anything that is not an ARN or an alias or fleet ID is taken to be a queue name
+func parseDeployment(value string) deployment {
+	value = strings.TrimSpace(value)
+
+	if match := gameLiftArnPattern.FindStringSubmatch(value); match != nil {
+		kind := deploymentQueue
+		switch match[1] {
+		case "alias":
+			kind = deploymentAlias
+		case "fleet":
+			kind = deploymentFleet
+		}
+
+		return deployment{kind: kind, value: value, id: match[2]}
+	}
+
+	if match := gameLiftIdPattern.FindStringSubmatch(value); match != nil {
+		kind := deploymentAlias
+		if match[1] == "fleet" {
+			kind = deploymentFleet
+		}
+
+		return deployment{kind: kind, value: value, id: value}
+	}
+
+	return deployment{kind: deploymentQueue, value: value, id: value}
+}
+
+// applyTo sets the AliasId or FleetId of a CreateGameSession request to match the deployment
+func (d deployment) applyTo(input *gamelift.CreateGameSessionInput) error {
+	value := d.value
+	switch d.kind {
+	case deploymentAlias:
+		input.AliasId = &value
+	case deploymentFleet:
+		input.FleetId = &value
+	default:
+		return fmt.Errorf("deployment %q is not a GameLift alias or fleet", d.value)
+	}
+
+	return nil
+}
+
+// describeGameSessionsInput lists the game sessions of the deployment in a location
+func (d deployment) describeGameSessionsInput(location string) *gamelift.DescribeGameSessionsInput {
+	value := d.value
+	input := &gamelift.DescribeGameSessionsInput{Location: &location}
+	if d.kind == deploymentFleet {
+		input.FleetId = &value
+	} else {
+		input.AliasId = &value
+	}
+
+	return input
+}
+
+// resolveFleetId returns the fleet behind the deployment, calling ResolveAlias for aliases
+func resolveFleetId(ctx context.Context, client AmazonGameLiftClient, d deployment) (string, error) {
+	switch d.kind {
+	case deploymentFleet:
+		return d.id, nil
+	case deploymentAlias:
+		resolveResponse, err := client.ResolveAlias(ctx, &gamelift.ResolveAliasInput{AliasId: &d.value})
+		if err != nil {
+			return "", err
+		}
+		if resolveResponse.FleetId == nil {
+			return "", fmt.Errorf("alias %s does not route to a fleet", d.value)
+		}
+
+		return *resolveResponse.FleetId, nil
+	default:
+		return "", fmt.Errorf("deployment %q is not a GameLift alias or fleet", d.value)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDeployment(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		kind  deploymentKind
+		id    string
+	}{
+		{
+			name:  "Alias ID",
+			value: "alias-8959a83a-b6ca-469c-9b84-394dedc64a6f",
+			kind:  deploymentAlias,
+			id:    "alias-8959a83a-b6ca-469c-9b84-394dedc64a6f",
+		},
+		{
+			name:  "Alias ARN",
+			value: "arn:aws:gamelift:us-west-2::alias/alias-8959a83a-b6ca-469c-9b84-394dedc64a6f",
+			kind:  deploymentAlias,
+			id:    "alias-8959a83a-b6ca-469c-9b84-394dedc64a6f",
+		},
+		{
+			name:  "Fleet ID",
+			value: "fleet-2222bbbb-33cc-44dd-55ee-666666ffffff",
+			kind:  deploymentFleet,
+			id:    "fleet-2222bbbb-33cc-44dd-55ee-666666ffffff",
+		},
+		{
+			name:  "Fleet ARN",
+			value: "arn:aws:gamelift:us-west-2:111122223333:fleet/fleet-2222bbbb-33cc-44dd-55ee-666666ffffff",
+			kind:  deploymentFleet,
+			id:    "fleet-2222bbbb-33cc-44dd-55ee-666666ffffff",
+		},
+		{
+			name:  "Queue name",
+			value: "my-queue",
+			kind:  deploymentQueue,
+			id:    "my-queue",
+		},
+		{
+			name:  "Queue name starting with fleet-",
+			value: "fleet-queue-usw2",
+			kind:  deploymentQueue,
+			id:    "fleet-queue-usw2",
+		},
+		{
+			name:  "Queue name starting with alias-",
+			value: "alias-1234",
+			kind:  deploymentQueue,
+			id:    "alias-1234",
+		},
+		{
+			name:  "Queue ARN",
+			value: "arn:aws:gamelift:us-west-2:111122223333:gamesessionqueue/my-queue",
+			kind:  deploymentQueue,
+			id:    "my-queue",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := parseDeployment(tt.value)
+			assert.Equal(t, tt.kind, d.kind)
+			assert.Equal(t, tt.id, d.id)
+			assert.Equal(t, tt.value, d.value)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/AccelByte/accelbyte-go-sdk/services-api/pkg/service/session"
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/runtime/client"
+	"github.com/go-openapi/strfmt"
+)
+
+// updateDSInformationPath is the session admin endpoint that sets the dedicated server of a game session
+const updateDSInformationPath = "/session/v1/admin/namespaces/{namespace}/gamesessions/{sessionId}/dsinformation"
+
+// DSInformation is the dedicated server information reported to AGS for a game session
+type DSInformation struct {
+	Status        string `json:"status"`
+	Ip            string `json:"ip,omitempty"`
+	Port          int64  `json:"port,omitempty"`
+	ServerId      string `json:"serverId,omitempty"`
+	Source        string `json:"source,omitempty"`
+	Deployment    string `json:"deployment,omitempty"`
+	Region        string `json:"region,omitempty"`
+	CreatedRegion string `json:"createdRegion,omitempty"`
+	ClientVersion string `json:"clientVersion,omitempty"`
+	GameMode      string `json:"gameMode,omitempty"`
+	Description   string `json:"description,omitempty"`
+}
+
+// AccelByteDSInformationClient reports the dedicated server of a game session to AGS
+type AccelByteDSInformationClient interface {
+	UpdateDSInformation(ctx context.Context, namespace, sessionId string, information DSInformation) error
+}
+
+// sessionAdminClient calls the session admin API through the transport and credentials of the AccelByte session client
+// The AccelByte Go SDK doesn't cover the DS information endpoint, so the operation is submitted directly
+type sessionAdminClient struct {
+	service *session.GameSessionService
+}
+
+func NewDSInformationClient(service *session.GameSessionService) AccelByteDSInformationClient {
+	return &sessionAdminClient{service: service}
+}
+
+func (c *sessionAdminClient) UpdateDSInformation(ctx context.Context, namespace, sessionId string, information DSInformation) error {
+	token, err := c.service.TokenRepository.GetToken()
+	if err != nil {
+		return err
+	}
+	if token.AccessToken == nil {
+		return fmt.Errorf("no access token to update the DS information of session %s", sessionId)
+	}
+
+	_, err = c.service.Client.Runtime.Submit(&runtime.ClientOperation{
+		ID:                 "adminUpdateDSInformation",
+		Method:             "PUT",
+		PathPattern:        updateDSInformationPath,
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"https"},
+		Params: runtime.ClientRequestWriterFunc(func(r runtime.ClientRequest, _ strfmt.Registry) error {
+			if err := r.SetPathParam("namespace", namespace); err != nil {
+				return err
+			}
+			if err := r.SetPathParam("sessionId", sessionId); err != nil {
+				return err
+			}
+
+			return r.SetBodyParam(information)
+		}),
+		Reader: runtime.ClientResponseReaderFunc(func(response runtime.ClientResponse, _ runtime.Consumer) (interface{}, error) {
+			if response.Code() >= 200 && response.Code() < 300 {
+				return nil, nil
+			}
+
+			body, _ := io.ReadAll(io.LimitReader(response.Body(), 1024))
+			return nil, fmt.Errorf("session service returned %d: %s", response.Code(), body)
+		}),
+		AuthInfo: client.BearerToken(*token.AccessToken),
+		Context:  ctx,
+	})
+
+	return err
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AccelByte/accelbyte-go-sdk/session-sdk/pkg/sessionclient/game_session"
+	"github.com/aws/smithy-go"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	errorDomainGameLift       = "gamelift.amazonaws.com"
+	errorDomainSessionService = "session.accelbyte.io"
+	errorDomainSessionDSM     = "session-dsm"
+
+	// defaultRetryDelay is the back-off suggested to callers for retryable failures
+	defaultRetryDelay = time.Second
+)
+
+// gameLiftErrorMapping describes how a GameLift error code is reported over gRPC
+type gameLiftErrorMapping struct {
+	code      codes.Code
+	retryable bool
+}
+
+// gameLiftErrorMappings is keyed by the error code returned by the AWS SDK
+var gameLiftErrorMappings = map[string]gameLiftErrorMapping{
+	"FleetCapacityExceededException":       {code: codes.ResourceExhausted, retryable: true},
+	"OutOfCapacityException":               {code: codes.ResourceExhausted, retryable: true},
+	"GameSessionFullException":             {code: codes.ResourceExhausted, retryable: false},
+	"LimitExceededException":               {code: codes.ResourceExhausted, retryable: false},
+	"InvalidRequestException":              {code: codes.InvalidArgument, retryable: false},
+	"UnsupportedRegionException":           {code: codes.InvalidArgument, retryable: false},
+	"NotFoundException":                    {code: codes.NotFound, retryable: false},
+	"ThrottlingException":                  {code: codes.Unavailable, retryable: true},
+	"TooManyRequestsException":             {code: codes.Unavailable, retryable: true},
+	"InternalServiceException":             {code: codes.Unavailable, retryable: true},
+	"ServiceUnavailableException":          {code: codes.Unavailable, retryable: true},
+	"UnauthorizedException":                {code: codes.PermissionDenied, retryable: false},
+	"AccessDeniedException":                {code: codes.PermissionDenied, retryable: false},
+	"ConflictException":                    {code: codes.Aborted, retryable: true},
+	"IdempotentParameterMismatchException": {code: codes.AlreadyExists, retryable: false},
+	"InvalidFleetStatusException":          {code: codes.FailedPrecondition, retryable: true},
+	"InvalidGameSessionStatusException":    {code: codes.FailedPrecondition, retryable: false},
+	"TerminalRoutingStrategyException":     {code: codes.FailedPrecondition, retryable: false},
+	"NotReadyException":                    {code: codes.FailedPrecondition, retryable: true},
+}
+
+var camelCaseBoundary = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+
+// gameLiftError translates an error returned by a GameLift call into a gRPC status error
+// The status carries an ErrorInfo with the GameLift error code, and a RetryInfo when the call may succeed if retried
+func gameLiftError(operation string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	if contextErr := contextError(operation, err); contextErr != nil {
+		return contextErr
+	}
+
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return newStatusError(codes.Unknown, errorDomainGameLift, "UNKNOWN", operation, false, err.Error())
+	}
+
+	mapping := classifyGameLiftError(apiErr)
+
+	return newStatusError(mapping.code, errorDomainGameLift, errorReason(apiErr.ErrorCode()), operation, mapping.retryable, apiErr.Error())
+}
+
+// isTransientGameLiftError reports whether an error points at a temporary problem with the GameLift target
+// rather than at the request itself, such as missing capacity, throttling, server faults or timeouts
+func isTransientGameLiftError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	return classifyGameLiftError(apiErr).retryable
+}
+
+func classifyGameLiftError(apiErr smithy.APIError) gameLiftErrorMapping {
+	mapping, ok := gameLiftErrorMappings[apiErr.ErrorCode()]
+	if !ok {
+		mapping = gameLiftErrorMapping{code: codes.Unknown, retryable: apiErr.ErrorFault() == smithy.FaultServer}
+	}
+
+	return mapping
+}
+
+// sessionServiceError translates an error returned by the AccelByte Session service into a gRPC status error
+func sessionServiceError(operation string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if contextErr := contextError(operation, err); contextErr != nil {
+		return contextErr
+	}
+
+	var badRequest *game_session.GetGameSessionBadRequest
+	var unauthorized *game_session.GetGameSessionUnauthorized
+	var forbidden *game_session.GetGameSessionForbidden
+	var notFound *game_session.GetGameSessionNotFound
+	var internalServerError *game_session.GetGameSessionInternalServerError
+
+	switch {
+	case errors.As(err, &badRequest):
+		return newStatusError(codes.InvalidArgument, errorDomainSessionService, "BAD_REQUEST", operation, false, err.Error())
+	case errors.As(err, &unauthorized):
+		return newStatusError(codes.Unauthenticated, errorDomainSessionService, "UNAUTHORIZED", operation, false, err.Error())
+	case errors.As(err, &forbidden):
+		return newStatusError(codes.PermissionDenied, errorDomainSessionService, "FORBIDDEN", operation, false, err.Error())
+	case errors.As(err, &notFound):
+		return newStatusError(codes.NotFound, errorDomainSessionService, "NOT_FOUND", operation, false, err.Error())
+	case errors.As(err, &internalServerError):
+		return newStatusError(codes.Unavailable, errorDomainSessionService, "INTERNAL_SERVER_ERROR", operation, true, err.Error())
+	default:
+		return newStatusError(codes.Unknown, errorDomainSessionService, "UNKNOWN", operation, false, err.Error())
+	}
+}
+
+// invalidArgumentError reports a request that the plugin rejects before calling GameLift
+func invalidArgumentError(reason string, format string, args ...interface{}) error {
+	return newStatusError(codes.InvalidArgument, errorDomainSessionDSM, reason, "", false, fmt.Sprintf(format, args...))
+}
+
+// failedPreconditionError reports a request that cannot be served with the current configuration
+func failedPreconditionError(reason string, format string, args ...interface{}) error {
+	return newStatusError(codes.FailedPrecondition, errorDomainSessionDSM, reason, "", false, fmt.Sprintf(format, args...))
+}
+
+func contextError(operation string, err error) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return newStatusError(codes.DeadlineExceeded, errorDomainSessionDSM, "DEADLINE_EXCEEDED", operation, true, err.Error())
+	case errors.Is(err, context.Canceled):
+		return newStatusError(codes.Canceled, errorDomainSessionDSM, "CANCELED", operation, false, err.Error())
+	default:
+		return nil
+	}
+}
+
+func newStatusError(code codes.Code, domain string, reason string, operation string, retryable bool, message string) error {
+	st := status.New(code, message)
+
+	metadata := map[string]string{
+		"retryable": strconv.FormatBool(retryable),
+	}
+	if operation != "" {
+		metadata["operation"] = operation
+	}
+
+	details := []protoadapt.MessageV1{
+		&errdetails.ErrorInfo{
+			Reason:   reason,
+			Domain:   domain,
+			Metadata: metadata,
+		},
+	}
+	if retryable {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(defaultRetryDelay)})
+	}
+
+	detailed, err := st.WithDetails(details...)
+	if err != nil {
+		return st.Err()
+	}
+
+	return detailed.Err()
+}
+
+// errorReason converts an AWS error code such as FleetCapacityExceededException into FLEET_CAPACITY_EXCEEDED
+func errorReason(errorCode string) string {
+	reason := strings.TrimSuffix(errorCode, "Exception")
+	reason = camelCaseBoundary.ReplaceAllString(reason, "${1}_${2}")
+
+	return strings.ToUpper(reason)
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/gamelift/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGameLiftError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		code      codes.Code
+		reason    string
+		retryable bool
+	}{
+		{
+			name:      "Fleet capacity exceeded",
+			err:       fmt.Errorf("operation error: %w", &types.FleetCapacityExceededException{}),
+			code:      codes.ResourceExhausted,
+			reason:    "FLEET_CAPACITY_EXCEEDED",
+			retryable: true,
+		},
+		{
+			name:   "Invalid request",
+			err:    &types.InvalidRequestException{},
+			code:   codes.InvalidArgument,
+			reason: "INVALID_REQUEST",
+		},
+		{
+			name:      "Throttling",
+			err:       &smithy.GenericAPIError{Code: "ThrottlingException", Fault: smithy.FaultClient},
+			code:      codes.Unavailable,
+			reason:    "THROTTLING",
+			retryable: true,
+		},
+		{
+			name:   "Unauthorized",
+			err:    &types.UnauthorizedException{},
+			code:   codes.PermissionDenied,
+			reason: "UNAUTHORIZED",
+		},
+		{
+			name:      "Deadline exceeded",
+			err:       fmt.Errorf("attempt: %w", context.DeadlineExceeded),
+			code:      codes.DeadlineExceeded,
+			reason:    "DEADLINE_EXCEEDED",
+			retryable: true,
+		},
+		{
+			name:   "Unknown error",
+			err:    errors.New("boom"),
+			code:   codes.Unknown,
+			reason: "UNKNOWN",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := status.Convert(gameLiftError("CreateGameSession", tt.err))
+			assert.Equal(t, tt.code, st.Code())
+
+			var errorInfo *errdetails.ErrorInfo
+			var retryInfo *errdetails.RetryInfo
+			for _, detail := range st.Details() {
+				switch d := detail.(type) {
+				case *errdetails.ErrorInfo:
+					errorInfo = d
+				case *errdetails.RetryInfo:
+					retryInfo = d
+				}
+			}
+
+			if assert.NotNil(t, errorInfo) {
+				assert.Equal(t, tt.reason, errorInfo.Reason)
+				assert.Equal(t, "CreateGameSession", errorInfo.Metadata["operation"])
+			}
+			assert.Equal(t, tt.retryable, retryInfo != nil)
+		})
+	}
+}
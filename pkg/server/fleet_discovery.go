@@ -0,0 +1,234 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/gamelift"
+	"github.com/aws/aws-sdk-go-v2/service/gamelift/types"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+)
+
+// describeFleetAttributesBatchSize is how many fleets are described in a single DescribeFleetAttributes call
+const describeFleetAttributesBatchSize = 10
+
+// discoveredFleet is an active fleet and the alias that routes to it, if any
+type discoveredFleet struct {
+	fleetId      string
+	aliasId      string
+	creationTime time.Time
+}
+
+// deployment returns the alias of the fleet when there is one, so alias updates keep working, and the fleet otherwise
+func (f discoveredFleet) deployment() string {
+	if f.aliasId != "" {
+		return f.aliasId
+	}
+
+	return f.fleetId
+}
+
+// FleetDiscovery periodically indexes the active fleets of the account by the version of the build they run
+// CreateGameSession uses it to send each request to a fleet whose build version matches the client version
+type FleetDiscovery struct {
+	client   AmazonGameLiftClient
+	interval time.Duration
+
+	mu         sync.RWMutex
+	discovered bool
+	byVersion  map[string]discoveredFleet
+
+	// buildVersions caches build versions across refreshes, since builds can't change once uploaded
+	buildVersions map[string]string
+}
+
+func NewFleetDiscovery(client AmazonGameLiftClient, interval time.Duration) *FleetDiscovery {
+	return &FleetDiscovery{
+		client:        client,
+		interval:      interval,
+		byVersion:     make(map[string]discoveredFleet),
+		buildVersions: make(map[string]string),
+	}
+}
+
+// errFleetDiscoveryPending is returned until the first fleet discovery completes
+var errFleetDiscoveryPending = newStatusError(codes.Unavailable, errorDomainSessionDSM, "FLEET_DISCOVERY_PENDING", "", true, "fleet discovery has not completed yet")
+
+// Run refreshes the fleet index right away and then on the configured interval until ctx is done
+func (d *FleetDiscovery) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.refresh(ctx); err != nil {
+			logrus.Warnf("Failed to discover GameLift fleets, keeping the previous fleet index: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Deployment returns the alias or fleet that runs the build with the client version
+func (d *FleetDiscovery) Deployment(clientVersion string) (string, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if !d.discovered {
+		return "", errFleetDiscoveryPending
+	}
+
+	fleet, ok := d.byVersion[clientVersion]
+	if !ok {
+		return "", failedPreconditionError("NO_COMPATIBLE_BUILD", "no active GameLift fleet runs a build with version %q", clientVersion)
+	}
+
+	return fleet.deployment(), nil
+}
+
+func (d *FleetDiscovery) refresh(ctx context.Context) error {
+	fleetIds, err := d.listFleets(ctx)
+	if err != nil {
+		return err
+	}
+
+	fleetAliases, err := d.listFleetAliases(ctx)
+	if err != nil {
+		return err
+	}
+
+	byVersion := make(map[string]discoveredFleet)
+	for start := 0; start < len(fleetIds); start += describeFleetAttributesBatchSize {
+		end := min(start+describeFleetAttributesBatchSize, len(fleetIds))
+		attributesResponse, err := d.client.DescribeFleetAttributes(ctx, &gamelift.DescribeFleetAttributesInput{
+			FleetIds: fleetIds[start:end],
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, attributes := range attributesResponse.FleetAttributes {
+			// Anywhere and container fleets don't run an uploaded build
+			if attributes.Status != types.FleetStatusActive || attributes.FleetId == nil || attributes.BuildId == nil {
+				continue
+			}
+
+			version, err := d.buildVersion(ctx, *attributes.BuildId)
+			if err != nil {
+				logrus.Warnf("Failed to describe build %s of fleet %s: %v", *attributes.BuildId, *attributes.FleetId, err)
+				continue
+			}
+			if version == "" {
+				continue
+			}
+
+			fleet := discoveredFleet{fleetId: *attributes.FleetId, aliasId: fleetAliases[*attributes.FleetId]}
+			if attributes.CreationTime != nil {
+				fleet.creationTime = *attributes.CreationTime
+			}
+
+			// The newest fleet wins when several run the same build version
+			if existing, ok := byVersion[version]; !ok || fleet.creationTime.After(existing.creationTime) {
+				byVersion[version] = fleet
+			}
+		}
+	}
+
+	d.mu.Lock()
+	d.byVersion = byVersion
+	d.discovered = true
+	d.mu.Unlock()
+
+	logrus.Debugf("Discovered GameLift fleets by build version: %v", byVersion)
+
+	return nil
+}
+
+func (d *FleetDiscovery) listFleets(ctx context.Context) ([]string, error) {
+	var fleetIds []string
+	input := &gamelift.ListFleetsInput{}
+	for {
+		listResponse, err := d.client.ListFleets(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		fleetIds = append(fleetIds, listResponse.FleetIds...)
+
+		if listResponse.NextToken == nil {
+			return fleetIds, nil
+		}
+		input.NextToken = listResponse.NextToken
+	}
+}
+
+// listFleetAliases returns the alias of each fleet that has a simple alias pointing to it
+// When several aliases point to the same fleet, the first one in alphabetical order is used
+func (d *FleetDiscovery) listFleetAliases(ctx context.Context) (map[string]string, error) {
+	var aliases []types.Alias
+	input := &gamelift.ListAliasesInput{RoutingStrategyType: types.RoutingStrategyTypeSimple}
+	for {
+		listResponse, err := d.client.ListAliases(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		aliases = append(aliases, listResponse.Aliases...)
+
+		if listResponse.NextToken == nil {
+			break
+		}
+		input.NextToken = listResponse.NextToken
+	}
+
+	sort.Slice(aliases, func(i, j int) bool {
+		return aliasId(aliases[i]) < aliasId(aliases[j])
+	})
+
+	fleetAliases := make(map[string]string)
+	for _, alias := range aliases {
+		if alias.AliasId == nil || alias.RoutingStrategy == nil || alias.RoutingStrategy.FleetId == nil {
+			continue
+		}
+		if _, ok := fleetAliases[*alias.RoutingStrategy.FleetId]; !ok {
+			fleetAliases[*alias.RoutingStrategy.FleetId] = *alias.AliasId
+		}
+	}
+
+	return fleetAliases, nil
+}
+
+func (d *FleetDiscovery) buildVersion(ctx context.Context, buildId string) (string, error) {
+	if version, ok := d.buildVersions[buildId]; ok {
+		return version, nil
+	}
+
+	buildResponse, err := d.client.DescribeBuild(ctx, &gamelift.DescribeBuildInput{BuildId: &buildId})
+	if err != nil {
+		return "", err
+	}
+
+	var version string
+	if buildResponse.Build != nil && buildResponse.Build.Version != nil {
+		version = *buildResponse.Build.Version
+	}
+	d.buildVersions[buildId] = version
+
+	return version, nil
+}
+
+func aliasId(alias types.Alias) string {
+	if alias.AliasId == nil {
+		return ""
+	}
+
+	return *alias.AliasId
+}
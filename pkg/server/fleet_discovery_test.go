@@ -0,0 +1,91 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/gamelift"
+	"github.com/aws/aws-sdk-go-v2/service/gamelift/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeFleetClient struct {
+	AmazonGameLiftClient
+
+	fleets  []types.FleetAttributes
+	aliases []types.Alias
+	builds  map[string]string
+}
+
+func (c *fakeFleetClient) ListFleets(context.Context, *gamelift.ListFleetsInput, ...func(*gamelift.Options)) (*gamelift.ListFleetsOutput, error) {
+	var fleetIds []string
+	for _, fleet := range c.fleets {
+		fleetIds = append(fleetIds, *fleet.FleetId)
+	}
+
+	return &gamelift.ListFleetsOutput{FleetIds: fleetIds}, nil
+}
+
+func (c *fakeFleetClient) DescribeFleetAttributes(_ context.Context, input *gamelift.DescribeFleetAttributesInput, _ ...func(*gamelift.Options)) (*gamelift.DescribeFleetAttributesOutput, error) {
+	var attributes []types.FleetAttributes
+	for _, fleet := range c.fleets {
+		for _, fleetId := range input.FleetIds {
+			if *fleet.FleetId == fleetId {
+				attributes = append(attributes, fleet)
+			}
+		}
+	}
+
+	return &gamelift.DescribeFleetAttributesOutput{FleetAttributes: attributes}, nil
+}
+
+func (c *fakeFleetClient) ListAliases(context.Context, *gamelift.ListAliasesInput, ...func(*gamelift.Options)) (*gamelift.ListAliasesOutput, error) {
+	return &gamelift.ListAliasesOutput{Aliases: c.aliases}, nil
+}
+
+func (c *fakeFleetClient) DescribeBuild(_ context.Context, input *gamelift.DescribeBuildInput, _ ...func(*gamelift.Options)) (*gamelift.DescribeBuildOutput, error) {
+	return &gamelift.DescribeBuildOutput{Build: &types.Build{BuildId: input.BuildId, Version: aws.String(c.builds[*input.BuildId])}}, nil
+}
+
+func TestFleetDiscovery(t *testing.T) {
+	now := time.Now()
+	client := &fakeFleetClient{
+		fleets: []types.FleetAttributes{
+			{FleetId: aws.String("fleet-old"), BuildId: aws.String("build-1"), Status: types.FleetStatusActive, CreationTime: aws.Time(now.Add(-time.Hour))},
+			{FleetId: aws.String("fleet-new"), BuildId: aws.String("build-2"), Status: types.FleetStatusActive, CreationTime: aws.Time(now)},
+			{FleetId: aws.String("fleet-next"), BuildId: aws.String("build-3"), Status: types.FleetStatusActive, CreationTime: aws.Time(now)},
+			{FleetId: aws.String("fleet-deleting"), BuildId: aws.String("build-4"), Status: types.FleetStatusDeleting},
+			{FleetId: aws.String("fleet-anywhere"), Status: types.FleetStatusActive},
+		},
+		aliases: []types.Alias{
+			{AliasId: aws.String("alias-next"), RoutingStrategy: &types.RoutingStrategy{FleetId: aws.String("fleet-next")}},
+		},
+		builds: map[string]string{"build-1": "1.0.0", "build-2": "1.0.0", "build-3": "1.1.0", "build-4": "0.9.0"},
+	}
+	discovery := NewFleetDiscovery(client, time.Minute)
+
+	_, err := discovery.Deployment("1.0.0")
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	require.NoError(t, discovery.refresh(context.Background()))
+
+	deployment, err := discovery.Deployment("1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, "fleet-new", deployment)
+
+	deployment, err = discovery.Deployment("1.1.0")
+	require.NoError(t, err)
+	assert.Equal(t, "alias-next", deployment)
+
+	_, err = discovery.Deployment("0.9.0")
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
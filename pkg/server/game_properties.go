@@ -0,0 +1,92 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"errors"
+	"unicode/utf8"
+
+	"session-dsm-grpc-plugin/pkg/gameproperties"
+	sessiondsm "session-dsm-grpc-plugin/pkg/pb"
+	"session-dsm-grpc-plugin/pkg/sessionsecret"
+
+	"github.com/aws/aws-sdk-go-v2/service/gamelift/types"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	clientVersionKey = gameproperties.ClientVersionKey
+	gameModeKey      = gameproperties.GameModeKey
+	sessionSecretKey = gameproperties.SessionSecretKey
+)
+
+// buildGameProperties returns the game properties sent to GameLift for a new game session
+// These can be read and used by the dedicated server that ends up hosting this session
+// clientVersion, gameMode and sessionSecret are always sent, followed by the properties of GAME_PROPERTIES_MAPPING_PATH
+func (s *SessionDSM) buildGameProperties(req *sessiondsm.RequestCreateGameSession) ([]types.GameProperty, error) {
+	// The secret is replaced by its HMAC or an encrypted blob when SESSION_SECRET_MODE is set
+	// Errors must never include the secret, since they are logged and returned to AGS
+	sessionSecret, err := s.SessionSecret.Protect(req.Secret)
+	if errors.Is(err, sessionsecret.ErrSecretTooLong) {
+		return nil, failedPreconditionError("SESSION_SECRET_TOO_LONG", "%v", err)
+	}
+	if err != nil {
+		return nil, newStatusError(codes.Internal, errorDomainSessionDSM, "SESSION_SECRET_PROTECTION_FAILED", "", false, "failed to protect the session secret")
+	}
+
+	values := []gameproperties.Value{
+		{Key: clientVersionKey, Value: req.ClientVersion},
+		{Key: gameModeKey, Value: req.GameMode},
+		{Key: sessionSecretKey, Value: sessionSecret},
+	}
+
+	mappedValues, err := s.GamePropertyMapping.Resolve(req.SessionData)
+	if err != nil {
+		return nil, invalidArgumentError("INVALID_GAME_PROPERTY", "failed to map session data to game properties: %v", err)
+	}
+
+	for _, value := range mappedValues {
+		switch value.Key {
+		case clientVersionKey, gameModeKey, sessionSecretKey:
+			return nil, invalidArgumentError("INVALID_GAME_PROPERTY", "game property key %q is reserved", value.Key)
+		}
+	}
+	values = append(values, mappedValues...)
+
+	if err := validateGameProperties(values); err != nil {
+		return nil, err
+	}
+
+	gameProperties := make([]types.GameProperty, 0, len(values))
+	for _, value := range values {
+		key, value := value.Key, value.Value
+		gameProperties = append(gameProperties, types.GameProperty{
+			Key:   &key,
+			Value: &value,
+		})
+	}
+
+	return gameProperties, nil
+}
+
+// validateGameProperties checks the game properties against the GameLift limits before they are sent,
+// so the caller gets a clear error instead of a ValidationException from AWS
+func validateGameProperties(values []gameproperties.Value) error {
+	if len(values) > gameproperties.MaxProperties {
+		return invalidArgumentError("TOO_MANY_GAME_PROPERTIES", "%d game properties, GameLift allows at most %d", len(values), gameproperties.MaxProperties)
+	}
+
+	for _, value := range values {
+		if utf8.RuneCountInString(value.Key) > gameproperties.MaxKeyLength {
+			return invalidArgumentError("GAME_PROPERTY_KEY_TOO_LONG", "game property key %q is longer than %d characters", value.Key, gameproperties.MaxKeyLength)
+		}
+
+		if utf8.RuneCountInString(value.Value) > gameproperties.MaxValueLength {
+			return invalidArgumentError("GAME_PROPERTY_VALUE_TOO_LONG", "value of game property %q is longer than %d characters", value.Key, gameproperties.MaxValueLength)
+		}
+	}
+
+	return nil
+}
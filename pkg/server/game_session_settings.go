@@ -0,0 +1,46 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	sessiondsm "session-dsm-grpc-plugin/pkg/pb"
+
+	"github.com/aws/aws-sdk-go-v2/service/gamelift/types"
+)
+
+// gameSessionSettings describe a new game session the same way to CreateGameSession and StartGameSessionPlacement
+// so servers get the same context whether they were started directly or through a queue
+type gameSessionSettings struct {
+	maximumPlayers  int32
+	gameProperties  []types.GameProperty
+	gameSessionData *string // nil when the session data is empty
+	name            *string // nil when no name template is set
+}
+
+// buildGameSessionSettings builds the game properties, game session data and name of the game session for a request
+func (s *SessionDSM) buildGameSessionSettings(req *sessiondsm.RequestCreateGameSession) (gameSessionSettings, error) {
+	gameProperties, err := s.buildGameProperties(req)
+	if err != nil {
+		return gameSessionSettings{}, err
+	}
+
+	gameSessionData, err := s.encodeGameSessionData(req.SessionData)
+	if err != nil {
+		return gameSessionSettings{}, err
+	}
+
+	settings := gameSessionSettings{
+		maximumPlayers: int32(req.MaximumPlayer),
+		gameProperties: gameProperties,
+		name:           s.SessionNaming.name(req),
+	}
+
+	// Only provide session data if it's not empty
+	if gameSessionData != "" {
+		settings.gameSessionData = &gameSessionData
+	}
+
+	return settings, nil
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"context"
+	"testing"
+
+	"session-dsm-grpc-plugin/pkg/latency"
+	sessiondsm "session-dsm-grpc-plugin/pkg/pb"
+
+	"github.com/aws/aws-sdk-go-v2/service/gamelift"
+	"github.com/aws/aws-sdk-go-v2/service/gamelift/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePlacementStarter struct {
+	AmazonGameLiftClient
+
+	input *gamelift.StartGameSessionPlacementInput
+}
+
+func (c *fakePlacementStarter) StartGameSessionPlacement(_ context.Context, input *gamelift.StartGameSessionPlacementInput, _ ...func(*gamelift.Options)) (*gamelift.StartGameSessionPlacementOutput, error) {
+	c.input = input
+
+	return &gamelift.StartGameSessionPlacementOutput{GameSessionPlacement: &types.GameSessionPlacement{
+		PlacementId: input.PlacementId,
+		Status:      types.GameSessionPlacementStatePending,
+	}}, nil
+}
+
+func TestCreateGameSessionAsyncSendsGameSessionSettings(t *testing.T) {
+	client := &fakePlacementStarter{}
+	s := &SessionDSM{
+		GameLiftClient: client,
+		SessionNaming:  SessionNaming{Template: "{namespace}/{session_id}"},
+	}
+
+	response, err := s.CreateGameSessionAsync(context.Background(), &sessiondsm.RequestCreateGameSession{
+		SessionId:     "session-1",
+		Namespace:     "ns",
+		Deployment:    "example-queue",
+		SessionData:   `{"teams":[]}`,
+		MaximumPlayer: 8,
+		ClientVersion: "1.2.3",
+		GameMode:      "ranked",
+		Secret:        "secret",
+	})
+	require.NoError(t, err)
+	require.True(t, response.Success, response.Message)
+
+	gameProperties := make(map[string]string)
+	for _, property := range client.input.GameProperties {
+		gameProperties[*property.Key] = *property.Value
+	}
+	assert.Equal(t, map[string]string{clientVersionKey: "1.2.3", gameModeKey: "ranked", sessionSecretKey: "secret"}, gameProperties)
+	assert.Equal(t, `{"teams":[]}`, *client.input.GameSessionData)
+	assert.Equal(t, "ns/session-1", *client.input.GameSessionName)
+	assert.Equal(t, int32(8), *client.input.MaximumPlayerSessionCount)
+}
+
+func TestCreateGameSessionAsyncLocationPriority(t *testing.T) {
+	tests := []struct {
+		name     string
+		priority LocationPriority
+		want     *types.PriorityConfigurationOverride
+	}{
+		{
+			name:     "Queue",
+			priority: LocationPriorityQueue,
+		},
+		{
+			name:     "Requested",
+			priority: LocationPriorityRequested,
+			want: &types.PriorityConfigurationOverride{
+				LocationOrder:             []string{"us-east-1", "eu-west-1"},
+				PlacementFallbackStrategy: types.PlacementFallbackStrategyDefaultAfterSinglePass,
+			},
+		},
+		{
+			name:     "Requested only",
+			priority: LocationPriorityRequestedOnly,
+			want: &types.PriorityConfigurationOverride{
+				LocationOrder:             []string{"us-east-1", "eu-west-1"},
+				PlacementFallbackStrategy: types.PlacementFallbackStrategyNone,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakePlacementStarter{}
+			s := &SessionDSM{
+				GameLiftClient:   client,
+				LatencyIngester:  latency.Ingester{RegionMap: map[string]string{"us-east": "us-east-1"}},
+				LocationPriority: tt.priority,
+			}
+
+			response, err := s.CreateGameSessionAsync(context.Background(), &sessiondsm.RequestCreateGameSession{
+				SessionId:       "session-1",
+				Namespace:       "ns",
+				Deployment:      "example-queue",
+				RequestedRegion: []string{"us-east", "eu-west-1", "us-east-1"},
+				MaximumPlayer:   8,
+			})
+			require.NoError(t, err)
+			require.True(t, response.Success, response.Message)
+			assert.Equal(t, tt.want, client.input.PriorityConfigurationOverride)
+		})
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/gamelift"
+	"github.com/aws/aws-sdk-go-v2/service/gamelift/types"
+	"github.com/aws/smithy-go"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// maxIdempotencyTokenLength is the longest idempotency token GameLift accepts
+	maxIdempotencyTokenLength = 48
+
+	// maxTokenLookupPages caps how many pages of DescribeGameSessions are searched for a game session created with a token
+	maxTokenLookupPages = 10
+	tokenLookupPageSize = int32(100)
+)
+
+// idempotencyToken returns the token a session is created with in a region
+// Every region gets its own token, so a retry can't collide with, or silently return, a game session from another location
+func idempotencyToken(sessionId, region string) string {
+	regionHash := sha256.Sum256([]byte(region))
+	token := sessionId + "-" + hex.EncodeToString(regionHash[:4])
+	if len(token) <= maxIdempotencyTokenLength {
+		return token
+	}
+
+	tokenHash := sha256.Sum256([]byte(sessionId + "/" + region))
+
+	return hex.EncodeToString(tokenHash[:maxIdempotencyTokenLength/2])
+}
+
+// isReusableGameSession reports whether a game session found for a retried request can be handed to AGS again
+func isReusableGameSession(gameSession *types.GameSession) bool {
+	return gameSession != nil && (gameSession.Status == types.GameSessionStatusActive || gameSession.Status == types.GameSessionStatusActivating)
+}
+
+type gameSessionRecord struct {
+	gameSessionId string
+	expiresAt     time.Time
+}
+
+// GameSessionRecords remembers the game session created for each AGS session, so a retried request gets the same game session back
+type GameSessionRecords struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	records map[string]gameSessionRecord
+	now     func() time.Time
+}
+
+func NewGameSessionRecords(ttl time.Duration) *GameSessionRecords {
+	return &GameSessionRecords{
+		ttl:     ttl,
+		records: make(map[string]gameSessionRecord),
+		now:     time.Now,
+	}
+}
+
+// Get returns the game session ID recorded for the AGS session
+func (r *GameSessionRecords) Get(sessionId string) (string, bool) {
+	if r == nil {
+		return "", false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.records[sessionId]
+	if !ok || r.now().After(record.expiresAt) {
+		delete(r.records, sessionId)
+		return "", false
+	}
+
+	return record.gameSessionId, true
+}
+
+// Put records the game session created for the AGS session, and forgets expired records
+func (r *GameSessionRecords) Put(sessionId, gameSessionId string) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	for id, record := range r.records {
+		if now.After(record.expiresAt) {
+			delete(r.records, id)
+		}
+	}
+
+	r.records[sessionId] = gameSessionRecord{gameSessionId: gameSessionId, expiresAt: now.Add(r.ttl)}
+}
+
+// Delete forgets the game session of the AGS session
+func (r *GameSessionRecords) Delete(sessionId string) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.records, sessionId)
+}
+
+// recordedGameSession returns the game session recorded for a retried request, if it can still be used
+func (s *SessionDSM) recordedGameSession(ctx context.Context, log *logrus.Entry, sessionId string) *types.GameSession {
+	gameSessionId, ok := s.GameSessionRecords.Get(sessionId)
+	if !ok {
+		return nil
+	}
+
+	describeResponse, err := s.GameLiftClient.DescribeGameSessions(ctx, &gamelift.DescribeGameSessionsInput{
+		GameSessionId: &gameSessionId,
+	})
+	if err != nil || len(describeResponse.GameSessions) == 0 {
+		log.Warnf("Failed to describe recorded Game Session %s, creating a new one: %v", gameSessionId, err)
+		s.GameSessionRecords.Delete(sessionId)
+		return nil
+	}
+
+	gameSession := &describeResponse.GameSessions[0]
+	if !isReusableGameSession(gameSession) {
+		log.Infof("Recorded Game Session %s is %s, creating a new one", gameSessionId, gameSession.Status)
+		s.GameSessionRecords.Delete(sessionId)
+		return nil
+	}
+
+	return gameSession
+}
+
+// existingGameSessionForToken looks up the game session a previous request created in the region with the same token
+// GameLift answers a CreateGameSession whose token is already in use with IdempotentParameterMismatchException
+// when the rest of the request differs, e.g. because the game properties or session data changed between retries
+func (s *SessionDSM) existingGameSessionForToken(ctx context.Context, d deployment, region, token string, err error) (*types.GameSession, bool) {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) || apiErr.ErrorCode() != "IdempotentParameterMismatchException" {
+		return nil, false
+	}
+
+	limit := tokenLookupPageSize
+	input := d.describeGameSessionsInput(region)
+	input.Limit = &limit
+
+	for page := 0; page < maxTokenLookupPages; page++ {
+		describeResponse, err := s.GameLiftClient.DescribeGameSessions(ctx, input)
+		if err != nil {
+			return nil, false
+		}
+
+		for i := range describeResponse.GameSessions {
+			gameSession := &describeResponse.GameSessions[i]
+			if gameSession.GameSessionId != nil && strings.HasSuffix(*gameSession.GameSessionId, "/"+token) {
+				return gameSession, isReusableGameSession(gameSession)
+			}
+		}
+
+		if describeResponse.NextToken == nil {
+			break
+		}
+		input.NextToken = describeResponse.NextToken
+	}
+
+	return nil, false
+}
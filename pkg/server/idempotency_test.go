@@ -0,0 +1,51 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyToken(t *testing.T) {
+	sessionId := "0123456789abcdef0123456789abcdef"
+
+	westToken := idempotencyToken(sessionId, "us-west-2")
+	assert.True(t, strings.HasPrefix(westToken, sessionId+"-"))
+	assert.Equal(t, westToken, idempotencyToken(sessionId, "us-west-2"))
+	assert.NotEqual(t, westToken, idempotencyToken(sessionId, "us-east-1"))
+
+	longToken := idempotencyToken(strings.Repeat("a", 60), "us-west-2")
+	assert.Len(t, longToken, maxIdempotencyTokenLength)
+	assert.NotEqual(t, longToken, idempotencyToken(strings.Repeat("a", 60), "us-east-1"))
+}
+
+func TestGameSessionRecords(t *testing.T) {
+	now := time.Now()
+	records := NewGameSessionRecords(time.Minute)
+	records.now = func() time.Time { return now }
+
+	records.Put("session-1", "arn:gamesession-1")
+	gameSessionId, ok := records.Get("session-1")
+	assert.True(t, ok)
+	assert.Equal(t, "arn:gamesession-1", gameSessionId)
+
+	records.Delete("session-1")
+	_, ok = records.Get("session-1")
+	assert.False(t, ok)
+
+	records.Put("session-2", "arn:gamesession-2")
+	now = now.Add(2 * time.Minute)
+	_, ok = records.Get("session-2")
+	assert.False(t, ok)
+
+	var disabled *GameSessionRecords
+	disabled.Put("session-3", "arn:gamesession-3")
+	_, ok = disabled.Get("session-3")
+	assert.False(t, ok)
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/gamelift/types"
+)
+
+// LocationPriority decides whose location order a queue placement follows, the queue's or the one requested by AGS
+type LocationPriority string
+
+const (
+	// LocationPriorityQueue keeps the priority configured on the queue and ignores the requested regions
+	LocationPriorityQueue LocationPriority = "queue"
+
+	// LocationPriorityRequested tries the requested regions in order first, then falls back to the queue's own priority
+	LocationPriorityRequested LocationPriority = "requested"
+
+	// LocationPriorityRequestedOnly only places sessions in the requested regions
+	LocationPriorityRequestedOnly LocationPriority = "requested_only"
+)
+
+func parseLocationPriority(value string) (LocationPriority, error) {
+	priority := LocationPriority(strings.ToLower(value))
+	switch priority {
+	case "":
+		return LocationPriorityQueue, nil
+	case LocationPriorityQueue, LocationPriorityRequested, LocationPriorityRequestedOnly:
+		return priority, nil
+	default:
+		return "", fmt.Errorf("unknown placement location priority %q, expected queue, requested or requested_only", value)
+	}
+}
+
+// priorityConfigurationOverride turns the regions requested by AGS into the location order of a queue placement
+// Returns nil when the queue's own priority should be used
+func (s *SessionDSM) priorityConfigurationOverride(priority LocationPriority, requestedRegions []string) *types.PriorityConfigurationOverride {
+	if priority != LocationPriorityRequested && priority != LocationPriorityRequestedOnly {
+		return nil
+	}
+
+	var locations []string
+	seen := make(map[string]bool)
+	for _, region := range requestedRegions {
+		location := s.LatencyIngester.Location(region)
+		if location == "" || seen[location] {
+			continue
+		}
+		seen[location] = true
+		locations = append(locations, location)
+	}
+
+	if len(locations) == 0 {
+		return nil
+	}
+
+	fallbackStrategy := types.PlacementFallbackStrategyDefaultAfterSinglePass
+	if priority == LocationPriorityRequestedOnly {
+		fallbackStrategy = types.PlacementFallbackStrategyNone
+	}
+
+	return &types.PriorityConfigurationOverride{
+		LocationOrder:             locations,
+		PlacementFallbackStrategy: fallbackStrategy,
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"session-dsm-grpc-plugin/pkg/routing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsNamespace = "session_dsm"
+
+var circuitBreakerState = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "circuit_breaker_state",
+		Help:      "State of the circuit breaker for a GameLift target: 0 closed, 1 open, 2 half-open.",
+	},
+	[]string{"target_type", "target", "location"},
+)
+
+var gameSessionRequests = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "game_session_requests_total",
+		Help:      "Game session creations and placements sent to GameLift, by routing variant and result.",
+	},
+	[]string{"operation", "variant", "target", "result"},
+)
+
+// recordGameSessionRequest counts the outcome of a request sent to GameLift, so canary and stable targets can be compared
+func recordGameSessionRequest(operation string, variant routing.Variant, target string, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+
+	gameSessionRequests.WithLabelValues(operation, string(variant), target, result).Inc()
+}
+
+// Reasons a termination never reached GameLift
+const (
+	skippedTerminationInvalidRequest      = "invalid_request"
+	skippedTerminationSessionNotFound     = "session_not_found"
+	skippedTerminationSessionServiceError = "session_service_error"
+	skippedTerminationInvalidDeployment   = "invalid_deployment"
+)
+
+var skippedTerminations = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "terminations_skipped_total",
+		Help:      "Game session terminations that never reached GameLift, by reason.",
+	},
+	[]string{"reason"},
+)
+
+func recordSkippedTermination(reason string) {
+	skippedTerminations.WithLabelValues(reason).Inc()
+}
+
+// Collectors returns the Prometheus collectors for the Session DSM's own metrics
+func Collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		circuitBreakerState,
+		gameSessionRequests,
+		skippedTerminations,
+	}
+}
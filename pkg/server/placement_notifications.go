@@ -0,0 +1,172 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"session-dsm-grpc-plugin/pkg/sns"
+
+	"github.com/aws/aws-sdk-go-v2/service/gamelift/types"
+	"github.com/sirupsen/logrus"
+)
+
+// maxNotificationSize caps the body of an SNS notification. SNS messages are at most 256 KiB, plus the envelope
+const maxNotificationSize = 300 * 1024
+
+// placementEventStates maps the placement event types GameLift publishes to the placement state they report
+var placementEventStates = map[string]types.GameSessionPlacementState{
+	"PlacementFulfilled": types.GameSessionPlacementStateFulfilled,
+	"PlacementTimedOut":  types.GameSessionPlacementStateTimedOut,
+	"PlacementCancelled": types.GameSessionPlacementStateCancelled,
+	"PlacementFailed":    types.GameSessionPlacementStateFailed,
+}
+
+// PlacementNotifications receives the placement events a GameLift queue publishes to an SNS topic
+type PlacementNotifications struct {
+	// TopicArn is the only topic messages are accepted from, and the only topic subscriptions are confirmed for
+	TopicArn string
+
+	Verifier *sns.Verifier
+}
+
+// placementEvent is the queue placement event GameLift publishes, see
+// https://docs.aws.amazon.com/gamelift/latest/developerguide/queue-events.html
+type placementEvent struct {
+	DetailType string `json:"detail-type"`
+	Detail     struct {
+		Type              string      `json:"type"`
+		PlacementId       string      `json:"placementId"`
+		GameSessionArn    string      `json:"gameSessionArn"`
+		GameSessionRegion string      `json:"gameSessionRegion"`
+		IpAddress         string      `json:"ipAddress"`
+		DnsName           string      `json:"dnsName"`
+		Port              json.Number `json:"port"`
+	} `json:"detail"`
+}
+
+// gameSessionPlacement returns the placement the event reports on, or nil for events that don't complete a placement
+func (e placementEvent) gameSessionPlacement() (*types.GameSessionPlacement, error) {
+	state, ok := placementEventStates[e.Detail.Type]
+	if !ok {
+		return nil, nil
+	}
+
+	if e.Detail.PlacementId == "" {
+		return nil, fmt.Errorf("%s event without a placement ID", e.Detail.Type)
+	}
+
+	placement := &types.GameSessionPlacement{
+		PlacementId: &e.Detail.PlacementId,
+		Status:      state,
+	}
+	if e.Detail.GameSessionArn != "" {
+		placement.GameSessionArn = &e.Detail.GameSessionArn
+	}
+	if e.Detail.GameSessionRegion != "" {
+		placement.GameSessionRegion = &e.Detail.GameSessionRegion
+	}
+	if e.Detail.IpAddress != "" {
+		placement.IpAddress = &e.Detail.IpAddress
+	}
+	if e.Detail.DnsName != "" {
+		placement.DnsName = &e.Detail.DnsName
+	}
+	if e.Detail.Port != "" {
+		port, err := e.Detail.Port.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("invalid port in %s event: %w", e.Detail.Type, err)
+		}
+		port32 := int32(port)
+		placement.Port = &port32
+	}
+
+	return placement, nil
+}
+
+// PlacementNotificationHandler serves the SNS subscription of the placement notifications
+// Subscription confirmations of the configured topic are confirmed, and placement events are reported to AGS like the placement tracker does
+// It serves plain HTTP, so TLS must be terminated in front of it
+func (s *SessionDSM) PlacementNotificationHandler() http.Handler {
+	return http.HandlerFunc(s.handlePlacementNotification)
+}
+
+func (s *SessionDSM) handlePlacementNotification(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxNotificationSize))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var message sns.Message
+	if err := json.Unmarshal(body, &message); err != nil {
+		logrus.Warnf("Received an SNS message that is not valid JSON: %v", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	log := logrus.WithFields(logrus.Fields{
+		"sns_message_id": message.MessageId,
+		"sns_topic_arn":  message.TopicArn,
+	})
+
+	// Without this check anyone could subscribe the endpoint to their own topic, and publish signed placement events
+	if s.PlacementNotifications.TopicArn == "" || message.TopicArn != s.PlacementNotifications.TopicArn {
+		log.Warnf("Rejected an SNS message from an unexpected topic")
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if err := s.PlacementNotifications.Verifier.Verify(r.Context(), &message); err != nil {
+		log.Warnf("Rejected an SNS message: %v", err)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	switch message.Type {
+	case sns.TypeSubscriptionConfirmation:
+		if err := s.PlacementNotifications.Verifier.ConfirmSubscription(r.Context(), &message); err != nil {
+			log.Errorf("Failed to confirm the SNS subscription: %v", err)
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		log.Infof("Confirmed the SNS subscription for placement notifications")
+	case sns.TypeUnsubscribeConfirmation:
+		log.Warnf("The SNS subscription for placement notifications was removed")
+	case sns.TypeNotification:
+		var event placementEvent
+		if err := json.Unmarshal([]byte(message.Message), &event); err != nil {
+			log.Warnf("Ignored an SNS notification that is not a placement event: %v", err)
+			break
+		}
+
+		placement, err := event.gameSessionPlacement()
+		if err != nil {
+			log.Warnf("Ignored a placement event: %v", err)
+			break
+		}
+		if placement == nil {
+			log.Debugf("Ignored a %s placement event", event.Detail.Type)
+			break
+		}
+
+		// A failure is answered with a server error, so SNS delivers the event again
+		if err := s.completePlacement(r.Context(), placement); err != nil {
+			log.Errorf("Failed to report Game Session Placement %s to AGS: %v", *placement.PlacementId, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
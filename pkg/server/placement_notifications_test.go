@@ -0,0 +1,136 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"session-dsm-grpc-plugin/pkg/constants"
+	"session-dsm-grpc-plugin/pkg/sns"
+	"session-dsm-grpc-plugin/pkg/sns/snstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const placementTopicArn = "arn:aws:sns:us-west-2:123456789012:placements"
+
+// newSignedNotification returns an SNS notification signed with a locally generated certificate, and a verifier trusting it
+func newSignedNotification(t *testing.T, event string) (*sns.Message, *sns.Verifier) {
+	server := snstest.NewServer(t)
+
+	message := &sns.Message{
+		Type:             sns.TypeNotification,
+		MessageId:        "message-1",
+		TopicArn:         placementTopicArn,
+		Message:          event,
+		Timestamp:        time.Now().UTC().Format(time.RFC3339Nano),
+		SignatureVersion: "2",
+	}
+	server.Sign(t, message)
+
+	return message, server.Verifier()
+}
+
+func postNotification(t *testing.T, s *SessionDSM, message *sns.Message) int {
+	body, err := json.Marshal(message)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	s.PlacementNotificationHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/gamelift/placement-notifications", bytes.NewReader(body)))
+
+	return recorder.Code
+}
+
+func TestPlacementNotificationFulfilled(t *testing.T) {
+	message, verifier := newSignedNotification(t, `{
+		"detail-type": "GameLift Queue Placement Event",
+		"source": "aws.gamelift",
+		"detail": {
+			"type": "PlacementFulfilled",
+			"placementId": "session-1",
+			"port": "7777",
+			"gameSessionArn": "arn:aws:gamelift:us-west-2::gamesession/fleet-1234/gsess-5678",
+			"ipAddress": "10.0.0.1",
+			"dnsName": "ec2-10-0-0-1.compute.amazonaws.com",
+			"gameSessionRegion": "us-west-2"
+		}
+	}`)
+
+	dsInformation := &fakeDSInformationClient{updates: make(map[string]DSInformation)}
+	s := &SessionDSM{
+		DSInformationClient:    dsInformation,
+		PlacementTracker:       NewPlacementTracker(time.Second, time.Hour),
+		PlacementNotifications: &PlacementNotifications{TopicArn: placementTopicArn, Verifier: verifier},
+	}
+	s.PlacementTracker.track("session-1", trackedPlacement{namespace: "ns", sessionId: "session-1", addressMode: AddressIp})
+
+	assert.Equal(t, http.StatusOK, postNotification(t, s, message))
+
+	information := dsInformation.updates["session-1"]
+	assert.Equal(t, constants.ServerStatusReady, information.Status)
+	assert.Equal(t, "10.0.0.1", information.Ip)
+	assert.Equal(t, int64(7777), information.Port)
+	assert.Equal(t, "arn:aws:gamelift:us-west-2::gamesession/fleet-1234/gsess-5678", information.ServerId)
+	assert.Empty(t, s.PlacementTracker.pending())
+}
+
+func TestPlacementNotificationRejected(t *testing.T) {
+	message, verifier := newSignedNotification(t, `{"detail": {"type": "PlacementFailed", "placementId": "session-1"}}`)
+
+	dsInformation := &fakeDSInformationClient{updates: make(map[string]DSInformation)}
+	s := &SessionDSM{
+		DSInformationClient:    dsInformation,
+		PlacementTracker:       NewPlacementTracker(time.Second, time.Hour),
+		PlacementNotifications: &PlacementNotifications{TopicArn: placementTopicArn, Verifier: verifier},
+	}
+	s.PlacementTracker.track("session-1", trackedPlacement{namespace: "ns", sessionId: "session-1"})
+
+	tampered := *message
+	tampered.Message = `{"detail": {"type": "PlacementFulfilled", "placementId": "session-1", "ipAddress": "192.0.2.1"}}`
+	assert.Equal(t, http.StatusForbidden, postNotification(t, s, &tampered))
+
+	s.PlacementNotifications.TopicArn = "arn:aws:sns:us-west-2:123456789012:other"
+	assert.Equal(t, http.StatusForbidden, postNotification(t, s, message))
+
+	s.PlacementNotifications.TopicArn = ""
+	assert.Equal(t, http.StatusForbidden, postNotification(t, s, message))
+
+	assert.Empty(t, dsInformation.updates)
+	assert.Contains(t, s.PlacementTracker.pending(), "session-1")
+}
+
+func TestPlacementNotificationSubscriptionFromOtherTopic(t *testing.T) {
+	server := snstest.NewServer(t)
+	message := &sns.Message{
+		Type:             sns.TypeSubscriptionConfirmation,
+		MessageId:        "message-1",
+		Token:            "token",
+		TopicArn:         "arn:aws:sns:us-west-2:210987654321:attacker",
+		Message:          "You have chosen to subscribe to the topic",
+		SubscribeURL:     server.URL + snstest.ConfirmPath,
+		Timestamp:        time.Now().UTC().Format(time.RFC3339Nano),
+		SignatureVersion: "2",
+	}
+	server.Sign(t, message)
+
+	s := &SessionDSM{
+		PlacementTracker:       NewPlacementTracker(time.Second, time.Hour),
+		PlacementNotifications: &PlacementNotifications{TopicArn: placementTopicArn, Verifier: server.Verifier()},
+	}
+
+	assert.Equal(t, http.StatusForbidden, postNotification(t, s, message))
+	assert.False(t, server.Confirmed())
+
+	message.TopicArn = placementTopicArn
+	server.Sign(t, message)
+	assert.Equal(t, http.StatusOK, postNotification(t, s, message))
+	assert.True(t, server.Confirmed())
+}
@@ -0,0 +1,280 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"session-dsm-grpc-plugin/pkg/constants"
+
+	"github.com/aws/aws-sdk-go-v2/service/gamelift"
+	"github.com/aws/aws-sdk-go-v2/service/gamelift/types"
+	"github.com/sirupsen/logrus"
+)
+
+// trackedPlacement is what's needed to report the result of a game session placement to AGS
+type trackedPlacement struct {
+	namespace     string
+	sessionId     string
+	clientVersion string
+	gameMode      string
+	addressMode   AddressMode
+	startedAt     time.Time
+}
+
+// PlacementTracker remembers the game session placements started by CreateGameSessionAsync until their result is reported to AGS
+type PlacementTracker struct {
+	interval time.Duration
+
+	// maxAge is how long a placement is followed before it is reported as failed, in case GameLift never resolves it
+	maxAge time.Duration
+
+	mu         sync.Mutex
+	placements map[string]trackedPlacement
+	now        func() time.Time
+}
+
+func NewPlacementTracker(interval, maxAge time.Duration) *PlacementTracker {
+	return &PlacementTracker{
+		interval:   interval,
+		maxAge:     maxAge,
+		placements: make(map[string]trackedPlacement),
+		now:        time.Now,
+	}
+}
+
+// track starts following a placement
+func (t *PlacementTracker) track(placementId string, placement trackedPlacement) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	placement.startedAt = t.now()
+	t.placements[placementId] = placement
+}
+
+// get returns the placement when it is still being followed
+func (t *PlacementTracker) get(placementId string) (trackedPlacement, bool) {
+	if t == nil {
+		return trackedPlacement{}, false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	placement, ok := t.placements[placementId]
+
+	return placement, ok
+}
+
+// untrack stops following a placement once its result has been reported
+func (t *PlacementTracker) untrack(placementId string) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.placements, placementId)
+}
+
+// pending returns a copy of the placements still being followed, by placement ID
+func (t *PlacementTracker) pending() map[string]trackedPlacement {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	placements := make(map[string]trackedPlacement, len(t.placements))
+	for placementId, placement := range t.placements {
+		placements[placementId] = placement
+	}
+
+	return placements
+}
+
+func (t *PlacementTracker) expired(placement trackedPlacement) bool {
+	return t.maxAge > 0 && t.now().Sub(placement.startedAt) > t.maxAge
+}
+
+// runPlacementTracker polls the outstanding placements on the tracker interval until ctx is done
+func (s *SessionDSM) runPlacementTracker(ctx context.Context) {
+	ticker := time.NewTicker(s.PlacementTracker.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		s.pollPlacements(ctx)
+	}
+}
+
+// pollPlacements describes every outstanding placement, and reports the ones that are no longer PENDING to AGS
+// Placements that outlive the tracker's max age are stopped and reported as failed
+func (s *SessionDSM) pollPlacements(ctx context.Context) {
+	for placementId, placement := range s.PlacementTracker.pending() {
+		log := placementLog(placementId, placement)
+
+		if s.PlacementTracker.expired(placement) {
+			// Stop the placement first, so GameLift doesn't start a game session for a DS that AGS already sees as failed
+			stopped, err := s.stopPlacement(ctx, placementId)
+			if err != nil {
+				log.Warnf("Failed to stop expired Game Session Placement: %v", err)
+			} else if stopped.Status == types.GameSessionPlacementStateFulfilled {
+				// The placement was fulfilled in the meantime, so the game session is reported instead
+				if err := s.completePlacement(ctx, stopped); err != nil {
+					log.Warnf("Failed to report Game Session Placement to AGS, retrying on the next poll: %v", err)
+				}
+				continue
+			}
+
+			information := failedDSInformation(placement, fmt.Sprintf("game session placement %s was not resolved within %s", placementId, s.PlacementTracker.maxAge))
+			if err := s.reportPlacement(ctx, log, placementId, placement, information); err != nil {
+				log.Warnf("Failed to report expired Game Session Placement to AGS, retrying on the next poll: %v", err)
+			}
+			continue
+		}
+
+		describeResponse, err := s.GameLiftClient.DescribeGameSessionPlacement(ctx, &gamelift.DescribeGameSessionPlacementInput{
+			PlacementId: &placementId,
+		})
+		if err != nil {
+			log.Warnf("Failed to describe Game Session Placement: %v", err)
+			continue
+		}
+
+		if err := s.completePlacement(ctx, describeResponse.GameSessionPlacement); err != nil {
+			log.Warnf("Failed to report Game Session Placement to AGS, retrying on the next poll: %v", err)
+		}
+	}
+}
+
+// completePlacement reports the result of a tracked placement to AGS once GameLift has resolved it
+// Fulfilled placements report the address and game session ARN of the server, all other final states mark the DS as failed
+// Placements that are still PENDING, or that aren't tracked, are left alone
+func (s *SessionDSM) completePlacement(ctx context.Context, gameSessionPlacement *types.GameSessionPlacement) error {
+	if gameSessionPlacement == nil || gameSessionPlacement.PlacementId == nil {
+		return errors.New("GameLift returned a game session placement without an ID")
+	}
+
+	placementId := *gameSessionPlacement.PlacementId
+	placement, ok := s.PlacementTracker.get(placementId)
+	if !ok {
+		return nil
+	}
+	log := placementLog(placementId, placement)
+
+	var information DSInformation
+	switch gameSessionPlacement.Status {
+	case types.GameSessionPlacementStatePending:
+		return nil
+	case types.GameSessionPlacementStateFulfilled:
+		information = s.fulfilledDSInformation(ctx, log, placement, gameSessionPlacement)
+	default:
+		information = failedDSInformation(placement, fmt.Sprintf("game session placement %s ended with status %s", placementId, gameSessionPlacement.Status))
+	}
+
+	return s.reportPlacement(ctx, log, placementId, placement, information)
+}
+
+// reportPlacement sends the DS information of a placement to AGS and stops following the placement
+func (s *SessionDSM) reportPlacement(ctx context.Context, log *logrus.Entry, placementId string, placement trackedPlacement, information DSInformation) error {
+	if s.DSInformationClient == nil {
+		return errors.New("no AGS client to update the DS information with")
+	}
+
+	if err := s.DSInformationClient.UpdateDSInformation(ctx, placement.namespace, placement.sessionId, information); err != nil {
+		return err
+	}
+
+	s.PlacementTracker.untrack(placementId)
+	log.Infof("Reported Game Session Placement to AGS, DS status: %s", information.Status)
+
+	return nil
+}
+
+func (s *SessionDSM) fulfilledDSInformation(ctx context.Context, log *logrus.Entry, placement trackedPlacement, gameSessionPlacement *types.GameSessionPlacement) DSInformation {
+	// The ARN is what the server presents when it connects to the AccelByte DS Hub, and what TerminateGameSession is called with
+	var gameSessionArn string
+	switch {
+	case gameSessionPlacement.GameSessionArn != nil:
+		gameSessionArn = *gameSessionPlacement.GameSessionArn
+	case gameSessionPlacement.GameSessionId != nil:
+		gameSessionArn = *gameSessionPlacement.GameSessionId
+	}
+
+	var port int64
+	if gameSessionPlacement.Port != nil {
+		port = int64(*gameSessionPlacement.Port)
+	}
+
+	var region string
+	if gameSessionPlacement.GameSessionRegion != nil {
+		region = *gameSessionPlacement.GameSessionRegion
+	}
+
+	// The placement doesn't hold the fleet, but the game session ARN does, which is all the address mode needs
+	gameSession := &types.GameSession{
+		IpAddress: gameSessionPlacement.IpAddress,
+		DnsName:   gameSessionPlacement.DnsName,
+	}
+	if fleetId := fleetIdFromGameSessionArn(gameSessionArn); fleetId != "" {
+		gameSession.FleetId = &fleetId
+	}
+
+	return DSInformation{
+		Status:        constants.ServerStatusReady,
+		Ip:            s.gameSessionAddress(ctx, log, gameSession, placement.addressMode),
+		Port:          port,
+		ServerId:      gameSessionArn,
+		Source:        constants.GameServerSourceGamelift,
+		Deployment:    gameSessionArn,
+		Region:        region,
+		CreatedRegion: region,
+		ClientVersion: placement.clientVersion,
+		GameMode:      placement.gameMode,
+	}
+}
+
+func failedDSInformation(placement trackedPlacement, description string) DSInformation {
+	return DSInformation{
+		Status:        constants.ServerStatusFailed,
+		Source:        constants.GameServerSourceGamelift,
+		ClientVersion: placement.clientVersion,
+		GameMode:      placement.gameMode,
+		Description:   description,
+	}
+}
+
+// fleetIdFromGameSessionArn reads the fleet from a game session ARN, e.g. arn:aws:gamelift:us-west-2::gamesession/fleet-1234/gsess-5678
+func fleetIdFromGameSessionArn(gameSessionArn string) string {
+	_, resource, ok := strings.Cut(gameSessionArn, ":gamesession/")
+	if !ok {
+		return ""
+	}
+
+	fleetId, _, _ := strings.Cut(resource, "/")
+
+	return fleetId
+}
+
+func placementLog(placementId string, placement trackedPlacement) *logrus.Entry {
+	return logrus.WithFields(logrus.Fields{
+		"placement_id": placementId,
+		"session_id":   placement.sessionId,
+		"namespace":    placement.namespace,
+	})
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"session-dsm-grpc-plugin/pkg/constants"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/gamelift"
+	"github.com/aws/aws-sdk-go-v2/service/gamelift/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePlacementClient struct {
+	AmazonGameLiftClient
+
+	placements map[string]types.GameSessionPlacement
+}
+
+func (c *fakePlacementClient) DescribeGameSessionPlacement(_ context.Context, input *gamelift.DescribeGameSessionPlacementInput, _ ...func(*gamelift.Options)) (*gamelift.DescribeGameSessionPlacementOutput, error) {
+	placement, ok := c.placements[*input.PlacementId]
+	if !ok {
+		return nil, errors.New("placement not found")
+	}
+
+	return &gamelift.DescribeGameSessionPlacementOutput{GameSessionPlacement: &placement}, nil
+}
+
+type fakeDSInformationClient struct {
+	err     error
+	updates map[string]DSInformation
+}
+
+func (c *fakeDSInformationClient) UpdateDSInformation(_ context.Context, _, sessionId string, information DSInformation) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.updates[sessionId] = information
+
+	return nil
+}
+
+func TestPollPlacements(t *testing.T) {
+	gameSessionArn := "arn:aws:gamelift:us-west-2::gamesession/fleet-1234/gsess-5678"
+	gameLift := &fakePlacementClient{placements: map[string]types.GameSessionPlacement{
+		"fulfilled": {
+			PlacementId:       aws.String("fulfilled"),
+			Status:            types.GameSessionPlacementStateFulfilled,
+			GameSessionArn:    aws.String(gameSessionArn),
+			GameSessionRegion: aws.String("us-west-2"),
+			IpAddress:         aws.String("10.0.0.1"),
+			Port:              aws.Int32(7777),
+		},
+		"timed-out": {PlacementId: aws.String("timed-out"), Status: types.GameSessionPlacementStateTimedOut},
+		"pending":   {PlacementId: aws.String("pending"), Status: types.GameSessionPlacementStatePending},
+	}}
+	dsInformation := &fakeDSInformationClient{updates: make(map[string]DSInformation)}
+	s := &SessionDSM{
+		GameLiftClient:      gameLift,
+		DSInformationClient: dsInformation,
+		PlacementTracker:    NewPlacementTracker(time.Second, time.Hour),
+	}
+
+	for _, placementId := range []string{"fulfilled", "timed-out", "pending"} {
+		s.PlacementTracker.track(placementId, trackedPlacement{namespace: "ns", sessionId: placementId, gameMode: "ranked", addressMode: AddressIp})
+	}
+
+	s.pollPlacements(context.Background())
+
+	fulfilled := dsInformation.updates["fulfilled"]
+	assert.Equal(t, constants.ServerStatusReady, fulfilled.Status)
+	assert.Equal(t, "10.0.0.1", fulfilled.Ip)
+	assert.Equal(t, int64(7777), fulfilled.Port)
+	assert.Equal(t, gameSessionArn, fulfilled.ServerId)
+	assert.Equal(t, gameSessionArn, fulfilled.Deployment)
+	assert.Equal(t, "us-west-2", fulfilled.Region)
+	assert.Equal(t, "ranked", fulfilled.GameMode)
+
+	assert.Equal(t, constants.ServerStatusFailed, dsInformation.updates["timed-out"].Status)
+	assert.NotContains(t, dsInformation.updates, "pending")
+
+	pending := s.PlacementTracker.pending()
+	assert.Len(t, pending, 1)
+	assert.Contains(t, pending, "pending")
+}
+
+func TestPollPlacementsRetriesFailedUpdates(t *testing.T) {
+	gameLift := &fakePlacementClient{placements: map[string]types.GameSessionPlacement{
+		"failed": {PlacementId: aws.String("failed"), Status: types.GameSessionPlacementStateFailed},
+	}}
+	dsInformation := &fakeDSInformationClient{err: errors.New("session service unavailable"), updates: make(map[string]DSInformation)}
+	s := &SessionDSM{
+		GameLiftClient:      gameLift,
+		DSInformationClient: dsInformation,
+		PlacementTracker:    NewPlacementTracker(time.Second, time.Hour),
+	}
+	s.PlacementTracker.track("failed", trackedPlacement{namespace: "ns", sessionId: "failed"})
+
+	s.pollPlacements(context.Background())
+	require.Contains(t, s.PlacementTracker.pending(), "failed")
+
+	dsInformation.err = nil
+	s.pollPlacements(context.Background())
+	assert.Empty(t, s.PlacementTracker.pending())
+	assert.Equal(t, constants.ServerStatusFailed, dsInformation.updates["failed"].Status)
+}
+
+func TestPollPlacementsExpires(t *testing.T) {
+	now := time.Now()
+	gameLift := &fakeStopPlacementClient{fakePlacementClient: fakePlacementClient{placements: map[string]types.GameSessionPlacement{
+		"stuck": {PlacementId: aws.String("stuck"), Status: types.GameSessionPlacementStatePending},
+	}}}
+	dsInformation := &fakeDSInformationClient{updates: make(map[string]DSInformation)}
+	s := &SessionDSM{
+		GameLiftClient:      gameLift,
+		DSInformationClient: dsInformation,
+		PlacementTracker:    NewPlacementTracker(time.Second, time.Minute),
+	}
+	s.PlacementTracker.now = func() time.Time { return now }
+	s.PlacementTracker.track("stuck", trackedPlacement{namespace: "ns", sessionId: "stuck"})
+	s.PlacementTracker.track("lost", trackedPlacement{namespace: "ns", sessionId: "lost"})
+
+	s.PlacementTracker.now = func() time.Time { return now.Add(2 * time.Minute) }
+	s.pollPlacements(context.Background())
+
+	assert.Empty(t, s.PlacementTracker.pending())
+	assert.Equal(t, []string{"stuck"}, gameLift.stopped)
+	assert.Equal(t, constants.ServerStatusFailed, dsInformation.updates["stuck"].Status)
+
+	// A placement GameLift no longer knows can't be stopped, but is still reported as failed
+	assert.Equal(t, constants.ServerStatusFailed, dsInformation.updates["lost"].Status)
+}
+
+func TestPollPlacementsExpiredButFulfilled(t *testing.T) {
+	now := time.Now()
+	gameSessionArn := "arn:aws:gamelift:us-west-2::gamesession/fleet-1234/gsess-5678"
+	gameLift := &fakeStopPlacementClient{fakePlacementClient: fakePlacementClient{placements: map[string]types.GameSessionPlacement{
+		"late": {PlacementId: aws.String("late"), Status: types.GameSessionPlacementStateFulfilled, GameSessionArn: aws.String(gameSessionArn)},
+	}}}
+	dsInformation := &fakeDSInformationClient{updates: make(map[string]DSInformation)}
+	s := &SessionDSM{
+		GameLiftClient:      gameLift,
+		DSInformationClient: dsInformation,
+		PlacementTracker:    NewPlacementTracker(time.Second, time.Minute),
+	}
+	s.PlacementTracker.now = func() time.Time { return now }
+	s.PlacementTracker.track("late", trackedPlacement{namespace: "ns", sessionId: "late"})
+
+	s.PlacementTracker.now = func() time.Time { return now.Add(2 * time.Minute) }
+	s.pollPlacements(context.Background())
+
+	assert.Empty(t, s.PlacementTracker.pending())
+	assert.Empty(t, gameLift.stopped)
+	assert.Equal(t, constants.ServerStatusReady, dsInformation.updates["late"].Status)
+	assert.Equal(t, gameSessionArn, dsInformation.updates["late"].Deployment)
+}
+
+func TestFleetIdFromGameSessionArn(t *testing.T) {
+	assert.Equal(t, "fleet-1234", fleetIdFromGameSessionArn("arn:aws:gamelift:us-west-2::gamesession/fleet-1234/gsess-5678"))
+	assert.Equal(t, "fleet-1234", fleetIdFromGameSessionArn("arn:aws:gamelift:us-west-2::gamesession/fleet-1234/custom-location/token"))
+	assert.Equal(t, "", fleetIdFromGameSessionArn("gsess-5678"))
+}
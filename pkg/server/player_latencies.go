@@ -0,0 +1,29 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/gamelift/types"
+)
+
+// playerLatencies reads the player latencies of the session data, as sent to a placement and used to order regions
+func (s *SessionDSM) playerLatencies(sessionData string) ([]types.PlayerLatency, error) {
+	entries, err := s.LatencyIngester.Extract(sessionData)
+	if err != nil {
+		return nil, err
+	}
+
+	latencies := make([]types.PlayerLatency, 0, len(entries))
+	for _, entry := range entries {
+		entry := entry
+		latencies = append(latencies, types.PlayerLatency{
+			PlayerId:              &entry.PlayerId,
+			RegionIdentifier:      &entry.Region,
+			LatencyInMilliseconds: &entry.LatencyMs,
+		})
+	}
+
+	return latencies, nil
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/gamelift"
+	"github.com/aws/aws-sdk-go-v2/service/gamelift/types"
+)
+
+// maxPlayerSessionsPerCall is the most player sessions GameLift creates in a single CreatePlayerSessions call
+// Larger sessions are reserved in several calls
+const maxPlayerSessionsPerCall = 25
+
+// maxDesiredPlayerSessions is the most DesiredPlayerSessions a placement accepts, so it limits the players of async sessions
+const maxDesiredPlayerSessions = 25
+
+// playerSessionsField is the session data field the player session IDs are returned in, keyed by user ID
+const playerSessionsField = "gamelift_player_sessions"
+
+// sessionMemberData is the part of the AGS session data that lists who is in the session
+type sessionMemberData struct {
+	Teams []struct {
+		UserIDs []string `json:"userIDs"`
+	} `json:"teams"`
+	Members []struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+	} `json:"members"`
+}
+
+// sessionPlayerIds returns the user IDs of the teams and of the joined members in the session data, without duplicates
+func sessionPlayerIds(sessionData string) ([]string, error) {
+	if sessionData == "" {
+		return nil, nil
+	}
+
+	var data sessionMemberData
+	if err := json.Unmarshal([]byte(sessionData), &data); err != nil {
+		return nil, fmt.Errorf("failed to read session members from session data: %w", err)
+	}
+
+	var playerIds []string
+	seen := make(map[string]bool)
+	add := func(userId string) {
+		if userId != "" && !seen[userId] {
+			seen[userId] = true
+			playerIds = append(playerIds, userId)
+		}
+	}
+
+	for _, team := range data.Teams {
+		for _, userId := range team.UserIDs {
+			add(userId)
+		}
+	}
+
+	for _, member := range data.Members {
+		switch member.Status {
+		case "", "JOINED", "CONNECTED":
+			add(member.ID)
+		}
+	}
+
+	return playerIds, nil
+}
+
+// playerIdsForSession reads the players to create player sessions for, and checks they fit in the session
+// A limit above zero caps the players as well, for requests that have to reserve all of them at once
+func playerIdsForSession(sessionData string, maximumPlayers int64, limit int) ([]string, error) {
+	playerIds, err := sessionPlayerIds(sessionData)
+	if err != nil {
+		return nil, invalidArgumentError("INVALID_SESSION_DATA", "%v", err)
+	}
+
+	if limit > 0 && len(playerIds) > limit {
+		return nil, invalidArgumentError("TOO_MANY_PLAYER_SESSIONS", "%d session members, GameLift reserves at most %d player sessions per placement", len(playerIds), limit)
+	}
+
+	if maximumPlayers > 0 && int64(len(playerIds)) > maximumPlayers {
+		return nil, invalidArgumentError("TOO_MANY_PLAYER_SESSIONS", "%d session members, but the session allows at most %d players", len(playerIds), maximumPlayers)
+	}
+
+	return playerIds, nil
+}
+
+// createPlayerSessions reserves a player slot on the game session for every player, and returns their player session IDs by player ID
+// Players that already hold a slot from an earlier attempt of the same request keep it
+func (s *SessionDSM) createPlayerSessions(ctx context.Context, gameSession *types.GameSession, playerIds []string) (map[string]string, error) {
+	playerSessions, err := s.existingPlayerSessions(ctx, gameSession)
+	if err != nil {
+		return nil, err
+	}
+
+	var missingPlayerIds []string
+	for _, playerId := range playerIds {
+		if _, ok := playerSessions[playerId]; !ok {
+			missingPlayerIds = append(missingPlayerIds, playerId)
+		}
+	}
+	if len(missingPlayerIds) == 0 {
+		return playerSessions, nil
+	}
+
+	// A batch that fails leaves the earlier ones in place, and the retry from AGS finds them with DescribePlayerSessions
+	for start := 0; start < len(missingPlayerIds); start += maxPlayerSessionsPerCall {
+		end := min(start+maxPlayerSessionsPerCall, len(missingPlayerIds))
+		createResponse, err := s.GameLiftClient.CreatePlayerSessions(ctx, &gamelift.CreatePlayerSessionsInput{
+			GameSessionId: gameSession.GameSessionId,
+			PlayerIds:     missingPlayerIds[start:end],
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, playerSession := range createResponse.PlayerSessions {
+			if playerSession.PlayerId != nil && playerSession.PlayerSessionId != nil {
+				playerSessions[*playerSession.PlayerId] = *playerSession.PlayerSessionId
+			}
+		}
+	}
+
+	return playerSessions, nil
+}
+
+// existingPlayerSessions returns the player sessions already reserved on a game session, by player ID
+func (s *SessionDSM) existingPlayerSessions(ctx context.Context, gameSession *types.GameSession) (map[string]string, error) {
+	playerSessions := make(map[string]string)
+	var nextToken *string
+	for {
+		describeResponse, err := s.GameLiftClient.DescribePlayerSessions(ctx, &gamelift.DescribePlayerSessionsInput{
+			GameSessionId: gameSession.GameSessionId,
+			NextToken:     nextToken,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, playerSession := range describeResponse.PlayerSessions {
+			if playerSession.PlayerId == nil || playerSession.PlayerSessionId == nil {
+				continue
+			}
+
+			switch playerSession.Status {
+			case types.PlayerSessionStatusReserved, types.PlayerSessionStatusActive:
+				playerSessions[*playerSession.PlayerId] = *playerSession.PlayerSessionId
+			}
+		}
+
+		nextToken = describeResponse.NextToken
+		if nextToken == nil || *nextToken == "" {
+			return playerSessions, nil
+		}
+	}
+}
+
+// desiredPlayerSessions asks a placement to reserve a player slot for every player once the game session is created
+func desiredPlayerSessions(playerIds []string) []types.DesiredPlayerSession {
+	if len(playerIds) == 0 {
+		return nil
+	}
+
+	desired := make([]types.DesiredPlayerSession, 0, len(playerIds))
+	for _, playerId := range playerIds {
+		playerId := playerId
+		desired = append(desired, types.DesiredPlayerSession{PlayerId: &playerId})
+	}
+
+	return desired
+}
+
+// withPlayerSessions adds the player session IDs to the session data returned to AGS, so clients can present them to the server
+// Session data that is not a JSON object is returned unchanged
+func withPlayerSessions(sessionData string, playerSessions map[string]string) (string, error) {
+	fields := make(map[string]json.RawMessage)
+	if sessionData != "" {
+		if err := json.Unmarshal([]byte(sessionData), &fields); err != nil {
+			return sessionData, fmt.Errorf("session data is not a JSON object: %w", err)
+		}
+	}
+
+	encodedPlayerSessions, err := json.Marshal(playerSessions)
+	if err != nil {
+		return sessionData, err
+	}
+	fields[playerSessionsField] = encodedPlayerSessions
+
+	content, err := json.Marshal(fields)
+	if err != nil {
+		return sessionData, err
+	}
+
+	return string(content), nil
+}
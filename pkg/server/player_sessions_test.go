@@ -0,0 +1,123 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/gamelift"
+	"github.com/aws/aws-sdk-go-v2/service/gamelift/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionPlayerIds(t *testing.T) {
+	sessionData := `{
+		"teams": [{"userIDs": ["a", "b"]}, {"userIDs": ["c"]}],
+		"members": [
+			{"id": "a", "status": "JOINED"},
+			{"id": "d", "status": "CONNECTED"},
+			{"id": "e", "status": "LEFT"},
+			{"id": "f", "status": "INVITED"}
+		]
+	}`
+
+	playerIds, err := sessionPlayerIds(sessionData)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, playerIds)
+
+	_, err = playerIdsForSession(sessionData, 3, 0)
+	assert.Error(t, err)
+
+	_, err = playerIdsForSession(sessionData, 0, 3)
+	assert.Error(t, err)
+
+	playerIds, err = playerIdsForSession(sessionData, 0, 0)
+	require.NoError(t, err)
+	assert.Len(t, playerIds, 4)
+
+	playerIds, err = playerIdsForSession("", 3, 0)
+	require.NoError(t, err)
+	assert.Empty(t, playerIds)
+}
+
+type fakePlayerSessionClient struct {
+	AmazonGameLiftClient
+
+	existing []types.PlayerSession
+	batches  [][]string
+}
+
+func (c *fakePlayerSessionClient) DescribePlayerSessions(_ context.Context, input *gamelift.DescribePlayerSessionsInput, _ ...func(*gamelift.Options)) (*gamelift.DescribePlayerSessionsOutput, error) {
+	// Hand out one existing session per page to exercise pagination
+	page := 0
+	if input.NextToken != nil {
+		fmt.Sscan(*input.NextToken, &page)
+	}
+	if page >= len(c.existing) {
+		return &gamelift.DescribePlayerSessionsOutput{}, nil
+	}
+
+	output := &gamelift.DescribePlayerSessionsOutput{PlayerSessions: c.existing[page : page+1]}
+	if page+1 < len(c.existing) {
+		output.NextToken = aws.String(fmt.Sprint(page + 1))
+	}
+
+	return output, nil
+}
+
+func (c *fakePlayerSessionClient) CreatePlayerSessions(_ context.Context, input *gamelift.CreatePlayerSessionsInput, _ ...func(*gamelift.Options)) (*gamelift.CreatePlayerSessionsOutput, error) {
+	c.batches = append(c.batches, input.PlayerIds)
+
+	output := &gamelift.CreatePlayerSessionsOutput{}
+	for _, playerId := range input.PlayerIds {
+		output.PlayerSessions = append(output.PlayerSessions, types.PlayerSession{
+			PlayerId:        aws.String(playerId),
+			PlayerSessionId: aws.String("psess-" + playerId),
+		})
+	}
+
+	return output, nil
+}
+
+func TestCreatePlayerSessionsInBatches(t *testing.T) {
+	gameLift := &fakePlayerSessionClient{existing: []types.PlayerSession{
+		{PlayerId: aws.String("p0"), PlayerSessionId: aws.String("psess-existing-0"), Status: types.PlayerSessionStatusReserved},
+		{PlayerId: aws.String("p1"), PlayerSessionId: aws.String("psess-existing-1"), Status: types.PlayerSessionStatusActive},
+		{PlayerId: aws.String("p2"), PlayerSessionId: aws.String("psess-timedout-2"), Status: types.PlayerSessionStatusTimedout},
+	}}
+	s := &SessionDSM{GameLiftClient: gameLift}
+
+	playerIds := make([]string, 0, 60)
+	for i := 0; i < 60; i++ {
+		playerIds = append(playerIds, fmt.Sprintf("p%d", i))
+	}
+
+	playerSessions, err := s.createPlayerSessions(context.Background(), &types.GameSession{GameSessionId: aws.String("gsess-1")}, playerIds)
+	require.NoError(t, err)
+	assert.Len(t, playerSessions, 60)
+	assert.Equal(t, "psess-existing-0", playerSessions["p0"])
+	assert.Equal(t, "psess-existing-1", playerSessions["p1"])
+	assert.Equal(t, "psess-p2", playerSessions["p2"])
+
+	require.Len(t, gameLift.batches, 3)
+	assert.Len(t, gameLift.batches[0], maxPlayerSessionsPerCall)
+	assert.Len(t, gameLift.batches[1], maxPlayerSessionsPerCall)
+	assert.Len(t, gameLift.batches[2], 8)
+	assert.Equal(t, "p2", gameLift.batches[0][0])
+}
+
+func TestWithPlayerSessions(t *testing.T) {
+	sessionData, err := withPlayerSessions(`{"teams":[]}`, map[string]string{"a": "psess-1"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"teams":[],"gamelift_player_sessions":{"a":"psess-1"}}`, sessionData)
+
+	sessionData, err = withPlayerSessions("not json", map[string]string{"a": "psess-1"})
+	assert.Error(t, err)
+	assert.Equal(t, "not json", sessionData)
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"session-dsm-grpc-plugin/pkg/latency"
+
+	"github.com/aws/aws-sdk-go-v2/service/gamelift/types"
+)
+
+// LatencyAggregate selects how the latencies of all players to one region are combined into a single score
+type LatencyAggregate string
+
+const (
+	LatencyAggregateMax  LatencyAggregate = "max"
+	LatencyAggregateMean LatencyAggregate = "mean"
+	LatencyAggregateP90  LatencyAggregate = "p90"
+)
+
+// LatencyOrdering ranks the requested regions of a synchronous CreateGameSession by player latency
+type LatencyOrdering struct {
+	// Aggregate combines player latencies per region. Empty disables ordering, so regions keep the order AGS sent
+	Aggregate LatencyAggregate
+
+	// CeilingMs drops regions whose aggregated latency is above this value. Zero disables the ceiling
+	// Regions without latency data are never dropped, since no player measured them rather than measuring them as slow
+	CeilingMs float32
+}
+
+func parseLatencyAggregate(value string) (LatencyAggregate, error) {
+	aggregate := LatencyAggregate(strings.ToLower(value))
+	switch aggregate {
+	case "", LatencyAggregateMax, LatencyAggregateMean, LatencyAggregateP90:
+		return aggregate, nil
+	default:
+		return "", fmt.Errorf("unknown latency aggregate %q, expected one of max, mean or p90", value)
+	}
+}
+
+// Order sorts regions by aggregated player latency, lowest first, and removes regions above the ceiling
+// Regions without any latency data are kept after the ranked ones, in their original order
+// Player latencies are keyed by GameLift location, so the requested regions are mapped through the ingester to match them
+func (o LatencyOrdering) Order(regions []string, playerLatencies []types.PlayerLatency, ingester latency.Ingester) []string {
+	latenciesByRegion := make(map[string][]float64)
+	for _, playerLatency := range playerLatencies {
+		if playerLatency.RegionIdentifier == nil || playerLatency.LatencyInMilliseconds == nil {
+			continue
+		}
+		location := strings.ToLower(strings.TrimSpace(*playerLatency.RegionIdentifier))
+		latenciesByRegion[location] = append(latenciesByRegion[location], float64(*playerLatency.LatencyInMilliseconds))
+	}
+
+	type rankedRegion struct {
+		region string
+		score  float64
+	}
+
+	var ranked []rankedRegion
+	var unranked []string
+	for _, region := range regions {
+		latencies, ok := latenciesByRegion[strings.ToLower(ingester.Location(region))]
+		if !ok {
+			unranked = append(unranked, region)
+			continue
+		}
+
+		score := o.aggregate(latencies)
+		if o.CeilingMs > 0 && score > float64(o.CeilingMs) {
+			continue
+		}
+		ranked = append(ranked, rankedRegion{region: region, score: score})
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].score < ranked[j].score
+	})
+
+	ordered := make([]string, 0, len(ranked)+len(unranked))
+	for _, r := range ranked {
+		ordered = append(ordered, r.region)
+	}
+
+	return append(ordered, unranked...)
+}
+
+func (o LatencyOrdering) aggregate(latencies []float64) float64 {
+	switch o.Aggregate {
+	case LatencyAggregateMean:
+		sum := 0.0
+		for _, latency := range latencies {
+			sum += latency
+		}
+
+		return sum / float64(len(latencies))
+	case LatencyAggregateP90:
+		sorted := append([]float64(nil), latencies...)
+		sort.Float64s(sorted)
+		index := int(math.Ceil(0.9*float64(len(sorted)))) - 1
+
+		return sorted[index]
+	default:
+		highest := latencies[0]
+		for _, latency := range latencies[1:] {
+			highest = math.Max(highest, latency)
+		}
+
+		return highest
+	}
+}
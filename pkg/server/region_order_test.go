@@ -0,0 +1,102 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"testing"
+
+	"session-dsm-grpc-plugin/pkg/latency"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/gamelift/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func playerLatency(playerId, region string, latencyMs float32) types.PlayerLatency {
+	return types.PlayerLatency{
+		PlayerId:              aws.String(playerId),
+		RegionIdentifier:      aws.String(region),
+		LatencyInMilliseconds: aws.Float32(latencyMs),
+	}
+}
+
+func TestLatencyOrderingOrder(t *testing.T) {
+	playerLatencies := []types.PlayerLatency{
+		playerLatency("player-1", "us-west-2", 40),
+		playerLatency("player-2", "us-west-2", 120),
+		playerLatency("player-1", "us-east-1", 80),
+		playerLatency("player-2", "us-east-1", 90),
+		playerLatency("player-1", "eu-west-1", 150),
+		playerLatency("player-2", "eu-west-1", 160),
+	}
+
+	tests := []struct {
+		name     string
+		ordering LatencyOrdering
+		regions  []string
+		expected []string
+	}{
+		{
+			name:     "max",
+			ordering: LatencyOrdering{Aggregate: LatencyAggregateMax},
+			regions:  []string{"eu-west-1", "us-west-2", "us-east-1"},
+			expected: []string{"us-east-1", "us-west-2", "eu-west-1"},
+		},
+		{
+			name:     "mean",
+			ordering: LatencyOrdering{Aggregate: LatencyAggregateMean},
+			regions:  []string{"eu-west-1", "us-east-1", "us-west-2"},
+			expected: []string{"us-west-2", "us-east-1", "eu-west-1"},
+		},
+		{
+			name:     "regions without data are kept last in requested order",
+			ordering: LatencyOrdering{Aggregate: LatencyAggregateMax},
+			regions:  []string{"ap-south-1", "eu-west-1", "sa-east-1", "us-east-1"},
+			expected: []string{"us-east-1", "eu-west-1", "ap-south-1", "sa-east-1"},
+		},
+		{
+			name:     "ceiling drops slow regions but not regions without data",
+			ordering: LatencyOrdering{Aggregate: LatencyAggregateMax, CeilingMs: 100},
+			regions:  []string{"eu-west-1", "ap-south-1", "us-west-2", "us-east-1"},
+			expected: []string{"us-east-1", "ap-south-1"},
+		},
+		{
+			name:     "requested regions are matched case-insensitively",
+			ordering: LatencyOrdering{Aggregate: LatencyAggregateMax},
+			regions:  []string{"EU-West-1", " us-east-1 "},
+			expected: []string{" us-east-1 ", "EU-West-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.ordering.Order(tt.regions, playerLatencies, latency.Ingester{}))
+		})
+	}
+}
+
+func TestLatencyOrderingOrderMapsRequestedRegions(t *testing.T) {
+	regionMap, err := latency.ParseRegionMap("us-east=us-east-1,eu-west=eu-west-1")
+	assert.NoError(t, err)
+	ingester := latency.Ingester{RegionMap: regionMap}
+
+	// Latencies are already stored by GameLift location, the requested AGS regions have to be mapped to match them
+	playerLatencies := []types.PlayerLatency{
+		playerLatency("player-1", "eu-west-1", 30),
+		playerLatency("player-1", "us-east-1", 90),
+	}
+
+	ordering := LatencyOrdering{Aggregate: LatencyAggregateMax, CeilingMs: 50}
+	assert.Equal(t, []string{"EU-WEST"}, ordering.Order([]string{"US-East", "EU-WEST"}, playerLatencies, ingester))
+}
+
+func TestLatencyOrderingAggregate(t *testing.T) {
+	latencies := []float64{10, 20, 30, 40, 50, 60, 70, 80, 90, 100}
+
+	assert.Equal(t, 100.0, LatencyOrdering{Aggregate: LatencyAggregateMax}.aggregate(latencies))
+	assert.Equal(t, 55.0, LatencyOrdering{Aggregate: LatencyAggregateMean}.aggregate(latencies))
+	assert.Equal(t, 90.0, LatencyOrdering{Aggregate: LatencyAggregateP90}.aggregate(latencies))
+	assert.Equal(t, 42.0, LatencyOrdering{Aggregate: LatencyAggregateP90}.aggregate([]float64{42}))
+}
@@ -0,0 +1,254 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/gamelift"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// defaultRetryableCodes are the GameLift error codes that are retried when GAMELIFT_RETRY_CODES is not set
+var defaultRetryableCodes = []string{
+	"ThrottlingException",
+	"TooManyRequestsException",
+	"InternalServiceException",
+	"ServiceUnavailableException",
+}
+
+// RetryPolicy controls how calls to GameLift are retried after a transient failure
+type RetryPolicy struct {
+	// MaxAttempts includes the first call, so 1 disables retries
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+
+	// Jitter is the fraction of each delay, between 0 and 1, that is randomized
+	Jitter float64
+
+	// RetryableCodes are GameLift error codes worth retrying. Any 5xx response is also retried
+	RetryableCodes map[string]bool
+}
+
+func newRetryableCodes(codes string) map[string]bool {
+	list := defaultRetryableCodes
+	if codes != "" {
+		list = strings.Split(codes, ",")
+	}
+
+	retryableCodes := make(map[string]bool, len(list))
+	for _, code := range list {
+		retryableCodes[strings.TrimSpace(code)] = true
+	}
+
+	return retryableCodes
+}
+
+func (p RetryPolicy) isRetryable(err error) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && p.RetryableCodes[apiErr.ErrorCode()] {
+		return true
+	}
+
+	var responseErr *smithyhttp.ResponseError
+	if errors.As(err, &responseErr) && responseErr.HTTPStatusCode() >= 500 {
+		return true
+	}
+
+	return false
+}
+
+// delay returns the exponential back-off before the given retry, with part of it randomized
+//
+//nolint:gosec
+func (p RetryPolicy) delay(retry int) time.Duration {
+	delay := p.BaseDelay << (retry - 1)
+	if delay > p.MaxDelay || delay <= 0 {
+		delay = p.MaxDelay
+	}
+
+	jitter := time.Duration(p.Jitter * rand.Float64() * float64(delay))
+
+	return delay - jitter
+}
+
+type retryLogKey struct{}
+
+// withRetryLog makes the GameLift calls made with the returned context log their retries through log,
+// so the retry logs keep the request fields such as session_id
+func withRetryLog(ctx context.Context, log *logrus.Entry) context.Context {
+	return context.WithValue(ctx, retryLogKey{}, log)
+}
+
+// retryLog returns the logger set by withRetryLog, or the standard logger for calls made outside a request
+func retryLog(ctx context.Context) *logrus.Entry {
+	if log, ok := ctx.Value(retryLogKey{}).(*logrus.Entry); ok {
+		return log
+	}
+
+	return logrus.NewEntry(logrus.StandardLogger())
+}
+
+// withRetry calls fn until it succeeds, fails with an error that is not retryable, or runs out of attempts
+// A retry is never started if its back-off would run past the context deadline
+// The number of retries is recorded on the span of ctx, even when there were none
+func withRetry[T any](ctx context.Context, log *logrus.Entry, policy RetryPolicy, operation string, fn func(ctx context.Context) (T, error)) (T, error) {
+	span := trace.SpanFromContext(ctx)
+	log = log.WithField("operation", operation)
+
+	retries := 0
+	defer func() {
+		span.SetAttributes(attribute.Int("gamelift."+operation+".retries", retries))
+	}()
+
+	for {
+		result, err := fn(ctx)
+		if err == nil || retries+1 >= policy.MaxAttempts || !policy.isRetryable(err) {
+			if retries > 0 {
+				log.WithField("retries", retries).Infof("GameLift %s finished after %d retries", operation, retries)
+			}
+
+			return result, err
+		}
+
+		delay := policy.delay(retries + 1)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			log.WithField("retries", retries).Warnf("Not retrying GameLift %s, back-off of %v would exceed the request deadline", operation, delay)
+
+			return result, err
+		}
+
+		log.WithField("retry", retries+1).Warnf("Retrying GameLift %s in %v after error: %v", operation, delay, err)
+		span.AddEvent("gamelift retry", trace.WithAttributes(
+			attribute.String("operation", operation),
+			attribute.Int("retry", retries+1),
+			attribute.String("error", err.Error()),
+		))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return result, err
+		case <-timer.C:
+		}
+		retries++
+	}
+}
+
+// retryingGameLiftClient applies a RetryPolicy to the calls the Session DSM makes to GameLift while serving a request
+// The SDK's own retryer is turned off for these calls, so GAMELIFT_RETRY_MAX_ATTEMPTS bounds every call that is made
+// The calls of background refreshes, such as fleet discovery and the capacity cache, keep the SDK's default retryer,
+// since they have no request to log against and are repeated on the next refresh anyway
+type retryingGameLiftClient struct {
+	AmazonGameLiftClient
+
+	policy RetryPolicy
+}
+
+func newRetryingGameLiftClient(client AmazonGameLiftClient, policy RetryPolicy) *retryingGameLiftClient {
+	return &retryingGameLiftClient{
+		AmazonGameLiftClient: client,
+		policy:               policy,
+	}
+}
+
+// withoutSDKRetries makes a call attempt only once in the SDK, so retries of the policy don't multiply with the SDK's
+func withoutSDKRetries(optFns []func(*gamelift.Options)) []func(*gamelift.Options) {
+	return append(optFns[:len(optFns):len(optFns)], func(o *gamelift.Options) {
+		o.Retryer = aws.NopRetryer{}
+	})
+}
+
+func (c *retryingGameLiftClient) CreateGameSession(
+	ctx context.Context,
+	input *gamelift.CreateGameSessionInput,
+	optFns ...func(*gamelift.Options),
+) (*gamelift.CreateGameSessionOutput, error) {
+	return withRetry(ctx, retryLog(ctx), c.policy, "CreateGameSession", func(ctx context.Context) (*gamelift.CreateGameSessionOutput, error) {
+		return c.AmazonGameLiftClient.CreateGameSession(ctx, input, withoutSDKRetries(optFns)...)
+	})
+}
+
+func (c *retryingGameLiftClient) TerminateGameSession(
+	ctx context.Context,
+	input *gamelift.TerminateGameSessionInput,
+	optFns ...func(*gamelift.Options),
+) (*gamelift.TerminateGameSessionOutput, error) {
+	return withRetry(ctx, retryLog(ctx), c.policy, "TerminateGameSession", func(ctx context.Context) (*gamelift.TerminateGameSessionOutput, error) {
+		return c.AmazonGameLiftClient.TerminateGameSession(ctx, input, withoutSDKRetries(optFns)...)
+	})
+}
+
+func (c *retryingGameLiftClient) StartGameSessionPlacement(
+	ctx context.Context,
+	input *gamelift.StartGameSessionPlacementInput,
+	optFns ...func(*gamelift.Options),
+) (*gamelift.StartGameSessionPlacementOutput, error) {
+	return withRetry(ctx, retryLog(ctx), c.policy, "StartGameSessionPlacement", func(ctx context.Context) (*gamelift.StartGameSessionPlacementOutput, error) {
+		return c.AmazonGameLiftClient.StartGameSessionPlacement(ctx, input, withoutSDKRetries(optFns)...)
+	})
+}
+
+func (c *retryingGameLiftClient) DescribeGameSessions(
+	ctx context.Context,
+	input *gamelift.DescribeGameSessionsInput,
+	optFns ...func(*gamelift.Options),
+) (*gamelift.DescribeGameSessionsOutput, error) {
+	return withRetry(ctx, retryLog(ctx), c.policy, "DescribeGameSessions", func(ctx context.Context) (*gamelift.DescribeGameSessionsOutput, error) {
+		return c.AmazonGameLiftClient.DescribeGameSessions(ctx, input, withoutSDKRetries(optFns)...)
+	})
+}
+
+func (c *retryingGameLiftClient) CreatePlayerSessions(
+	ctx context.Context,
+	input *gamelift.CreatePlayerSessionsInput,
+	optFns ...func(*gamelift.Options),
+) (*gamelift.CreatePlayerSessionsOutput, error) {
+	return withRetry(ctx, retryLog(ctx), c.policy, "CreatePlayerSessions", func(ctx context.Context) (*gamelift.CreatePlayerSessionsOutput, error) {
+		return c.AmazonGameLiftClient.CreatePlayerSessions(ctx, input, withoutSDKRetries(optFns)...)
+	})
+}
+
+func (c *retryingGameLiftClient) DescribePlayerSessions(
+	ctx context.Context,
+	input *gamelift.DescribePlayerSessionsInput,
+	optFns ...func(*gamelift.Options),
+) (*gamelift.DescribePlayerSessionsOutput, error) {
+	return withRetry(ctx, retryLog(ctx), c.policy, "DescribePlayerSessions", func(ctx context.Context) (*gamelift.DescribePlayerSessionsOutput, error) {
+		return c.AmazonGameLiftClient.DescribePlayerSessions(ctx, input, withoutSDKRetries(optFns)...)
+	})
+}
+
+func (c *retryingGameLiftClient) StopGameSessionPlacement(
+	ctx context.Context,
+	input *gamelift.StopGameSessionPlacementInput,
+	optFns ...func(*gamelift.Options),
+) (*gamelift.StopGameSessionPlacementOutput, error) {
+	return withRetry(ctx, retryLog(ctx), c.policy, "StopGameSessionPlacement", func(ctx context.Context) (*gamelift.StopGameSessionPlacementOutput, error) {
+		return c.AmazonGameLiftClient.StopGameSessionPlacement(ctx, input, withoutSDKRetries(optFns)...)
+	})
+}
+
+func (c *retryingGameLiftClient) DescribeGameSessionPlacement(
+	ctx context.Context,
+	input *gamelift.DescribeGameSessionPlacementInput,
+	optFns ...func(*gamelift.Options),
+) (*gamelift.DescribeGameSessionPlacementOutput, error) {
+	return withRetry(ctx, retryLog(ctx), c.policy, "DescribeGameSessionPlacement", func(ctx context.Context) (*gamelift.DescribeGameSessionPlacementOutput, error) {
+		return c.AmazonGameLiftClient.DescribeGameSessionPlacement(ctx, input, withoutSDKRetries(optFns)...)
+	})
+}
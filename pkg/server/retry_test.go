@@ -0,0 +1,163 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/gamelift"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/sirupsen/logrus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var errThrottled = &smithy.GenericAPIError{Code: "ThrottlingException", Message: "rate exceeded"}
+
+func TestRetryPolicyIsRetryable(t *testing.T) {
+	policy := RetryPolicy{RetryableCodes: newRetryableCodes("")}
+
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "retryable code", err: errThrottled, expected: true},
+		{name: "other code", err: &smithy.GenericAPIError{Code: "InvalidRequestException"}, expected: false},
+		{name: "server error", err: &smithyhttp.ResponseError{Response: &smithyhttp.Response{Response: &http.Response{StatusCode: http.StatusBadGateway}}, Err: errors.New("bad gateway")}, expected: true},
+		{name: "client error", err: &smithyhttp.ResponseError{Response: &smithyhttp.Response{Response: &http.Response{StatusCode: http.StatusBadRequest}}, Err: errors.New("bad request")}, expected: false},
+		{name: "other error", err: errors.New("connection refused"), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, policy.isRetryable(tt.err))
+		})
+	}
+
+	assert.True(t, RetryPolicy{RetryableCodes: newRetryableCodes("InvalidRequestException, ConflictException")}.isRetryable(&smithy.GenericAPIError{Code: "ConflictException"}))
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	assert.Equal(t, 100*time.Millisecond, policy.delay(1))
+	assert.Equal(t, 200*time.Millisecond, policy.delay(2))
+	assert.Equal(t, 800*time.Millisecond, policy.delay(4))
+	assert.Equal(t, time.Second, policy.delay(5))
+	assert.Equal(t, time.Second, policy.delay(100), "overflowing shifts are capped")
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		delay := policy.delay(2)
+		assert.GreaterOrEqual(t, delay, 100*time.Millisecond)
+		assert.LessOrEqual(t, delay, 200*time.Millisecond)
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, RetryableCodes: newRetryableCodes("")}
+
+	tests := []struct {
+		name            string
+		errs            []error
+		timeout         time.Duration
+		expectedCalls   int
+		expectedRetries int
+		expectedError   error
+	}{
+		{name: "succeeds first time", errs: []error{nil}, expectedCalls: 1, expectedRetries: 0},
+		{name: "succeeds after retries", errs: []error{errThrottled, errThrottled, nil}, expectedCalls: 3, expectedRetries: 2},
+		{name: "runs out of attempts", errs: []error{errThrottled, errThrottled, errThrottled, nil}, expectedCalls: 3, expectedRetries: 2, expectedError: errThrottled},
+		{name: "not retryable", errs: []error{errors.New("invalid input"), nil}, expectedCalls: 1, expectedRetries: 0, expectedError: errors.New("invalid input")},
+		{name: "back-off past the deadline", errs: []error{errThrottled, nil}, timeout: time.Microsecond, expectedCalls: 1, expectedRetries: 0, expectedError: errThrottled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			ctx, span := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test").Start(context.Background(), "test")
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+
+			calls := 0
+			result, err := withRetry(ctx, logrus.NewEntry(logrus.New()), policy, "CreateGameSession", func(context.Context) (int, error) {
+				err := tt.errs[calls]
+				calls++
+
+				return calls, err
+			})
+			span.End()
+
+			assert.Equal(t, tt.expectedError, err)
+			assert.Equal(t, tt.expectedCalls, result)
+			assert.Equal(t, tt.expectedCalls, calls)
+
+			require.Len(t, recorder.Ended(), 1)
+			assert.Contains(t, recorder.Ended()[0].Attributes(), attribute.Int("gamelift.CreateGameSession.retries", tt.expectedRetries))
+		})
+	}
+}
+
+func TestWithRetryLogsThroughRequestLogger(t *testing.T) {
+	logger, hook := logrustest.NewNullLogger()
+	ctx := withRetryLog(context.Background(), logger.WithField("session_id", "session-1"))
+
+	policy := RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, RetryableCodes: newRetryableCodes("")}
+	client := newRetryingGameLiftClient(&fakeDescribeClient{responses: []describeResponse{{err: errThrottled}, {}}}, policy)
+
+	_, err := client.DescribeGameSessions(ctx, nil)
+	require.NoError(t, err)
+
+	require.NotEmpty(t, hook.AllEntries())
+	for _, entry := range hook.AllEntries() {
+		assert.Equal(t, "session-1", entry.Data["session_id"])
+		assert.Equal(t, "DescribeGameSessions", entry.Data["operation"])
+	}
+}
+
+// fakeOptionsClient records the options a StopGameSessionPlacement call would be made with
+type fakeOptionsClient struct {
+	AmazonGameLiftClient
+
+	options []gamelift.Options
+}
+
+func (c *fakeOptionsClient) StopGameSessionPlacement(_ context.Context, _ *gamelift.StopGameSessionPlacementInput, optFns ...func(*gamelift.Options)) (*gamelift.StopGameSessionPlacementOutput, error) {
+	options := gamelift.Options{Retryer: retry.NewStandard()}
+	for _, fn := range optFns {
+		fn(&options)
+	}
+	c.options = append(c.options, options)
+
+	return nil, errThrottled
+}
+
+func TestRetryingGameLiftClientDisablesSDKRetries(t *testing.T) {
+	gameLift := &fakeOptionsClient{}
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, RetryableCodes: newRetryableCodes("")}
+
+	_, err := newRetryingGameLiftClient(gameLift, policy).StopGameSessionPlacement(context.Background(), &gamelift.StopGameSessionPlacementInput{})
+	assert.Equal(t, errThrottled, err)
+
+	// Every attempt of the policy is a single SDK attempt, so 3 attempts are 3 calls to GameLift
+	require.Len(t, gameLift.options, 3)
+	for _, options := range gameLift.options {
+		assert.Equal(t, 1, options.Retryer.MaxAttempts())
+	}
+}
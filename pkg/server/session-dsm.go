@@ -6,20 +6,30 @@ package server
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
+	"session-dsm-grpc-plugin/pkg/common"
 	"session-dsm-grpc-plugin/pkg/constants"
+	"session-dsm-grpc-plugin/pkg/gameproperties"
+	"session-dsm-grpc-plugin/pkg/latency"
 	sessiondsm "session-dsm-grpc-plugin/pkg/pb"
+	"session-dsm-grpc-plugin/pkg/routing"
+	"session-dsm-grpc-plugin/pkg/sessiondata"
+	"session-dsm-grpc-plugin/pkg/sessionsecret"
+	"session-dsm-grpc-plugin/pkg/sns"
 	"session-dsm-grpc-plugin/pkg/utils/envelope"
 
 	"github.com/AccelByte/accelbyte-go-sdk/session-sdk/pkg/sessionclient/game_session"
 	"github.com/AccelByte/accelbyte-go-sdk/session-sdk/pkg/sessionclientmodels"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/gamelift"
 	"github.com/aws/aws-sdk-go-v2/service/gamelift/types"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
 )
 
 type AccelByteSessionClient interface {
@@ -30,6 +40,17 @@ type AmazonGameLiftClient interface {
 	CreateGameSession(context.Context, *gamelift.CreateGameSessionInput, ...func(*gamelift.Options)) (*gamelift.CreateGameSessionOutput, error)
 	TerminateGameSession(context.Context, *gamelift.TerminateGameSessionInput, ...func(*gamelift.Options)) (*gamelift.TerminateGameSessionOutput, error)
 	StartGameSessionPlacement(context.Context, *gamelift.StartGameSessionPlacementInput, ...func(*gamelift.Options)) (*gamelift.StartGameSessionPlacementOutput, error)
+	DescribeGameSessions(context.Context, *gamelift.DescribeGameSessionsInput, ...func(*gamelift.Options)) (*gamelift.DescribeGameSessionsOutput, error)
+	ResolveAlias(context.Context, *gamelift.ResolveAliasInput, ...func(*gamelift.Options)) (*gamelift.ResolveAliasOutput, error)
+	DescribePlayerSessions(context.Context, *gamelift.DescribePlayerSessionsInput, ...func(*gamelift.Options)) (*gamelift.DescribePlayerSessionsOutput, error)
+	CreatePlayerSessions(context.Context, *gamelift.CreatePlayerSessionsInput, ...func(*gamelift.Options)) (*gamelift.CreatePlayerSessionsOutput, error)
+	ListFleets(context.Context, *gamelift.ListFleetsInput, ...func(*gamelift.Options)) (*gamelift.ListFleetsOutput, error)
+	ListAliases(context.Context, *gamelift.ListAliasesInput, ...func(*gamelift.Options)) (*gamelift.ListAliasesOutput, error)
+	DescribeFleetAttributes(context.Context, *gamelift.DescribeFleetAttributesInput, ...func(*gamelift.Options)) (*gamelift.DescribeFleetAttributesOutput, error)
+	DescribeBuild(context.Context, *gamelift.DescribeBuildInput, ...func(*gamelift.Options)) (*gamelift.DescribeBuildOutput, error)
+	DescribeFleetLocationUtilization(context.Context, *gamelift.DescribeFleetLocationUtilizationInput, ...func(*gamelift.Options)) (*gamelift.DescribeFleetLocationUtilizationOutput, error)
+	DescribeGameSessionPlacement(context.Context, *gamelift.DescribeGameSessionPlacementInput, ...func(*gamelift.Options)) (*gamelift.DescribeGameSessionPlacementOutput, error)
+	StopGameSessionPlacement(context.Context, *gamelift.StopGameSessionPlacementInput, ...func(*gamelift.Options)) (*gamelift.StopGameSessionPlacementOutput, error)
 }
 
 type SessionDSM struct {
@@ -39,38 +60,353 @@ type SessionDSM struct {
 	AwsLocationOverride string
 	AwsQueueArnOverride string
 
-	SessionClient  AccelByteSessionClient
-	GameLiftClient AmazonGameLiftClient
+	Routing                *routing.Store
+	RoutingReloadInterval  time.Duration
+	GamePropertyMapping    *gameproperties.Mapping
+	SessionDataEncoder     sessiondata.Encoder
+	SessionSecret          *sessionsecret.Protector
+	CreatePlayerSessions   bool
+	SessionNaming          SessionNaming
+	GameSessionRecords     *GameSessionRecords
+	AttemptStrategy        AttemptStrategy
+	LatencyIngester        latency.Ingester
+	LatencyOrdering        LatencyOrdering
+	ActivationWait         ActivationWait
+	CircuitBreakers        *CircuitBreakers
+	CapacityCache          *CapacityCache
+	FleetDiscovery         *FleetDiscovery
+	AddressMode            AddressMode
+	TLSFleets              *TLSFleets
+	LocationPriority       LocationPriority
+	PlacementTracker       *PlacementTracker
+	PlacementNotifications *PlacementNotifications
+
+	SessionClient       AccelByteSessionClient
+	DSInformationClient AccelByteDSInformationClient
+	GameLiftClient      AmazonGameLiftClient
 }
 
-func NewSessionDSM(SessionClient AccelByteSessionClient, GameLiftClient AmazonGameLiftClient) *SessionDSM {
+func NewSessionDSM(SessionClient AccelByteSessionClient, GameLiftClient AmazonGameLiftClient) (*SessionDSM, error) {
 	sessionDsm := SessionDSM{
 		SessionClient:  SessionClient,
 		GameLiftClient: GameLiftClient,
 	}
 
 	// Useful for testing
-	// Sets req.Deployment for all requests to CreateGameSession
+	// Sets req.Deployment for requests to CreateGameSession that the routing table doesn't cover
 	aliasIdOverride, ok := os.LookupEnv("AWS_ALIAS_ID_OVERRIDE")
 	if ok && aliasIdOverride != "" {
 		sessionDsm.AwsAliasIdOverride = aliasIdOverride
 	}
 
 	// Useful for testing with GameLift Servers Anywhere, which use custom locations
-	// Sets req.RequestedRegion for all requests to CreateGameSession
+	// Sets req.RequestedRegion for requests to CreateGameSession that the routing table doesn't cover
 	locationOverride, ok := os.LookupEnv("AWS_LOCATION_OVERRIDE")
 	if ok && locationOverride != "" {
 		sessionDsm.AwsLocationOverride = locationOverride
 	}
 
 	// Useful for testing GameLift queues/session placements
-	// Directs all requests to CreateGameSessionAsync to the given Queue
+	// Directs requests to CreateGameSessionAsync that the routing table doesn't cover to the given Queue
 	queueArnOverride, ok := os.LookupEnv("AWS_QUEUE_ARN_OVERRIDE")
 	if ok && queueArnOverride != "" {
 		sessionDsm.AwsQueueArnOverride = queueArnOverride
 	}
 
-	return &sessionDsm
+	// Routes requests to an alias, location list or queue based on namespace, deployment, game mode and client version
+	// The AWS_*_OVERRIDE values above are only used when no route matches, or the matched route leaves a field empty
+	// The file is checked for changes on an interval, so routes and canary percentages can be changed without a restart
+	routingTablePath, ok := os.LookupEnv("ROUTING_TABLE_PATH")
+	if ok && routingTablePath != "" {
+		routingStore, err := routing.NewStore(routingTablePath)
+		if err != nil {
+			return nil, err
+		}
+		sessionDsm.Routing = routingStore
+		sessionDsm.RoutingReloadInterval = time.Duration(common.GetEnvInt("ROUTING_TABLE_RELOAD_INTERVAL_MS", 10000)) * time.Millisecond
+	}
+
+	// Sends values from the session data, such as the map or team sizes, to GameLift as additional game properties
+	gamePropertyMappingPath, ok := os.LookupEnv("GAME_PROPERTIES_MAPPING_PATH")
+	if ok && gamePropertyMappingPath != "" {
+		gamePropertyMapping, err := gameproperties.Load(gamePropertyMappingPath)
+		if err != nil {
+			return nil, err
+		}
+		sessionDsm.GamePropertyMapping = gamePropertyMapping
+	}
+
+	// Keeps only these top-level fields of the session data in the GameSessionData sent to GameLift
+	gameSessionDataAllowlist := common.GetEnv("GAME_SESSION_DATA_ALLOWLIST", "")
+	if gameSessionDataAllowlist != "" {
+		for _, field := range strings.Split(gameSessionDataAllowlist, ",") {
+			sessionDsm.SessionDataEncoder.Allowlist = append(sessionDsm.SessionDataEncoder.Allowlist, strings.TrimSpace(field))
+		}
+	}
+
+	// Gzips and base64 encodes the GameSessionData, always with gzip or only when it would not fit otherwise with auto
+	gameSessionDataCompression, err := sessiondata.ParseCompression(common.GetEnv("GAME_SESSION_DATA_COMPRESSION", ""))
+	if err != nil {
+		return nil, err
+	}
+	sessionDsm.SessionDataEncoder.Compression = gameSessionDataCompression
+
+	// Sends the HMAC of the session secret, or the secret encrypted with AES-GCM, instead of the secret itself
+	// The key is read from a file holding it in base64, e.g. one mounted from a Kubernetes secret
+	sessionSecretMode, err := sessionsecret.ParseMode(common.GetEnv("SESSION_SECRET_MODE", ""))
+	if err != nil {
+		return nil, err
+	}
+	if sessionSecretMode != sessionsecret.ModePlaintext {
+		secretKey, err := sessionsecret.LoadKey(common.GetEnv("SESSION_SECRET_KEY_PATH", ""))
+		if err != nil {
+			return nil, err
+		}
+		sessionDsm.SessionSecret, err = sessionsecret.NewProtector(sessionSecretMode, secretKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Reserves a GameLift player session for every team member and joined member in the session data
+	// CreateGameSession then waits for the game session to become ACTIVE, since player sessions can't be created before that
+	sessionDsm.CreatePlayerSessions = strings.ToLower(common.GetEnv("CREATE_PLAYER_SESSIONS", "false")) == "true"
+
+	// Names game sessions after the AGS session, e.g. {namespace}/{game_mode}/{session_id}, so they can be found in the GameLift console
+	sessionDsm.SessionNaming.Template = common.GetEnv("GAME_SESSION_NAME_TEMPLATE", "")
+	if err := validateNameTemplate(sessionDsm.SessionNaming.Template); err != nil {
+		return nil, err
+	}
+
+	// Sets the CreatorId of game sessions created by CreateGameSession to the AGS session leader
+	// Note that GameLift then applies its per-creator resource creation limit policy to these requests
+	sessionDsm.SessionNaming.CreatorFromLeader = strings.ToLower(common.GetEnv("GAME_SESSION_CREATOR_FROM_LEADER", "false")) == "true"
+
+	// Remembers the game session created for each AGS session for this many milliseconds, so a retried request gets the same one back
+	// Leave at 0 to rely on the per-region idempotency tokens only
+	gameSessionRecordTtlMs := common.GetEnvInt("GAME_SESSION_RECORD_TTL_MS", 3600000)
+	if gameSessionRecordTtlMs > 0 {
+		sessionDsm.GameSessionRecords = NewGameSessionRecords(time.Duration(gameSessionRecordTtlMs) * time.Millisecond)
+	}
+
+	// Starts the next requested region in parallel if the current one has not answered within this many milliseconds
+	// Leave at 0 to try regions one after another. Either way, the request deadline is split across the regions
+	hedgeDelayMs := common.GetEnvInt("CREATE_SESSION_HEDGE_DELAY_MS", 0)
+	sessionDsm.AttemptStrategy.HedgeDelay = time.Duration(hedgeDelayMs) * time.Millisecond
+
+	// The smallest slice of the request deadline any single region attempt is given
+	minAttemptTimeoutMs := common.GetEnvInt("CREATE_SESSION_MIN_ATTEMPT_TIMEOUT_MS", 1000)
+	sessionDsm.AttemptStrategy.MinAttemptTimeout = time.Duration(minAttemptTimeoutMs) * time.Millisecond
+
+	// The time budget split across the region attempts of a request that AGS sent without a deadline
+	defaultAttemptTimeoutMs := common.GetEnvInt("CREATE_SESSION_DEFAULT_TIMEOUT_MS", int(DefaultAttemptTimeout/time.Millisecond))
+	if defaultAttemptTimeoutMs <= 0 {
+		return nil, fmt.Errorf("CREATE_SESSION_DEFAULT_TIMEOUT_MS must be greater than 0, got %d", defaultAttemptTimeoutMs)
+	}
+	sessionDsm.AttemptStrategy.DefaultTimeout = time.Duration(defaultAttemptTimeoutMs) * time.Millisecond
+
+	// Renames the AGS region names found in player latencies to GameLift location names, e.g. us-east=us-east-1
+	latencyRegionMap, err := latency.ParseRegionMap(common.GetEnv("LATENCY_REGION_MAP", ""))
+	if err != nil {
+		return nil, err
+	}
+	sessionDsm.LatencyIngester.RegionMap = latencyRegionMap
+
+	// Player latencies above this many milliseconds are dropped as failed measurements
+	maxLatencyMs, err := common.GetEnvFloat("LATENCY_MAX_MS", latency.DefaultMaxLatencyMs)
+	if err != nil {
+		return nil, err
+	}
+	if maxLatencyMs <= 0 {
+		return nil, fmt.Errorf("LATENCY_MAX_MS must be greater than 0, got %v", maxLatencyMs)
+	}
+	sessionDsm.LatencyIngester.MaxLatencyMs = maxLatencyMs
+
+	// Drops latencies more than this many interquartile ranges above the third quartile of the region, e.g. 1.5
+	// Leave at 0 to keep them
+	outlierFactor, err := common.GetEnvFloat("LATENCY_OUTLIER_IQR_FACTOR", 0)
+	if err != nil {
+		return nil, err
+	}
+	if outlierFactor < 0 {
+		return nil, fmt.Errorf("LATENCY_OUTLIER_IQR_FACTOR must not be negative, got %v", outlierFactor)
+	}
+	sessionDsm.LatencyIngester.OutlierFactor = outlierFactor
+
+	// Ranks the requested regions of CreateGameSession by player latency found in the session data
+	// Accepts max, mean or p90. Leave unset to keep the region order sent by AGS
+	latencyAggregate, err := parseLatencyAggregate(common.GetEnv("SYNC_LATENCY_AGGREGATE", ""))
+	if err != nil {
+		return nil, err
+	}
+	sessionDsm.LatencyOrdering.Aggregate = latencyAggregate
+
+	// Regions whose aggregated player latency is above this many milliseconds are not attempted
+	sessionDsm.LatencyOrdering.CeilingMs = float32(common.GetEnvInt("SYNC_LATENCY_CEILING_MS", 0))
+
+	// Waits for new game sessions to become ACTIVE before returning them, so AGS never sends players to a server that isn't listening yet
+	// Sessions that fail to activate are terminated and the next requested region is attempted
+	sessionDsm.ActivationWait.Enabled = strings.ToLower(common.GetEnv("WAIT_FOR_ACTIVE_GAME_SESSION", "false")) == "true"
+
+	// Reserving player sessions waits for activation too, so both values are checked even when the wait is disabled
+	pollIntervalMs := common.GetEnvInt("GAME_SESSION_POLL_INTERVAL_MS", 500)
+	if pollIntervalMs <= 0 {
+		return nil, fmt.Errorf("GAME_SESSION_POLL_INTERVAL_MS must be greater than 0, got %d", pollIntervalMs)
+	}
+	sessionDsm.ActivationWait.PollInterval = time.Duration(pollIntervalMs) * time.Millisecond
+
+	activationTimeoutMs := common.GetEnvInt("GAME_SESSION_ACTIVATION_TIMEOUT_MS", 30000)
+	if activationTimeoutMs <= 0 {
+		return nil, fmt.Errorf("GAME_SESSION_ACTIVATION_TIMEOUT_MS must be greater than 0, got %d", activationTimeoutMs)
+	}
+	sessionDsm.ActivationWait.Timeout = time.Duration(activationTimeoutMs) * time.Millisecond
+
+	// Retries GameLift calls that fail with throttling or transient server errors, with exponential back-off and jitter
+	// Retries are never started past the request deadline. Leave GAMELIFT_RETRY_MAX_ATTEMPTS at 1 to disable
+	retryJitterPercent := common.GetEnvInt("GAMELIFT_RETRY_JITTER_PERCENT", 50)
+	if retryJitterPercent < 0 || retryJitterPercent > 100 {
+		return nil, fmt.Errorf("GAMELIFT_RETRY_JITTER_PERCENT must be between 0 and 100, got %d", retryJitterPercent)
+	}
+	retryPolicy := RetryPolicy{
+		MaxAttempts:    common.GetEnvInt("GAMELIFT_RETRY_MAX_ATTEMPTS", 1),
+		BaseDelay:      time.Duration(common.GetEnvInt("GAMELIFT_RETRY_BASE_DELAY_MS", 100)) * time.Millisecond,
+		MaxDelay:       time.Duration(common.GetEnvInt("GAMELIFT_RETRY_MAX_DELAY_MS", 2000)) * time.Millisecond,
+		Jitter:         float64(retryJitterPercent) / 100,
+		RetryableCodes: newRetryableCodes(common.GetEnv("GAMELIFT_RETRY_CODES", "")),
+	}
+	if retryPolicy.MaxAttempts > 1 {
+		sessionDsm.GameLiftClient = newRetryingGameLiftClient(GameLiftClient, retryPolicy)
+	}
+
+	// Skips an (alias, location) pair or a queue for a cooldown after this many consecutive transient failures
+	// Leave CIRCUIT_BREAKER_FAILURE_THRESHOLD at 0 to disable
+	sessionDsm.CircuitBreakers = NewCircuitBreakers(CircuitBreakerConfig{
+		FailureThreshold: common.GetEnvInt("CIRCUIT_BREAKER_FAILURE_THRESHOLD", 0),
+		Cooldown:         time.Duration(common.GetEnvInt("CIRCUIT_BREAKER_COOLDOWN_MS", 30000)) * time.Millisecond,
+	})
+
+	// Skips, or moves to the back, locations whose fleet has no idle game server processes according to cached utilization data
+	// Accepts skip or deprioritize. Leave unset to disable
+	capacityPrecheckMode, err := parseCapacityPrecheckMode(common.GetEnv("CAPACITY_PRECHECK_MODE", ""))
+	if err != nil {
+		return nil, err
+	}
+	if capacityPrecheckMode != CapacityPrecheckOff {
+		capacityRefreshInterval := time.Duration(common.GetEnvInt("CAPACITY_REFRESH_INTERVAL_MS", 15000)) * time.Millisecond
+
+		// Locations that have not been requested for this long are no longer refreshed
+		capacityTrackingTtl := time.Duration(common.GetEnvInt("CAPACITY_TRACKING_TTL_MS", 3600000)) * time.Millisecond
+		sessionDsm.CapacityCache = NewCapacityCache(sessionDsm.GameLiftClient, capacityPrecheckMode, capacityRefreshInterval, capacityTrackingTtl)
+	}
+
+	// Returns the DNS name of game sessions instead of their IP, either always or only for fleets with generated TLS certificates
+	// Routes of the routing table can override this with their address field
+	addressMode, err := parseAddressMode(common.GetEnv("GAME_SESSION_ADDRESS", ""))
+	if err != nil {
+		return nil, err
+	}
+	sessionDsm.AddressMode = addressMode
+	sessionDsm.TLSFleets = NewTLSFleets()
+
+	// Indexes fleets by build version, so routes with discover_fleet can send CreateGameSession to the alias or fleet
+	// whose build version matches the client version
+	// Fleets are discovered with ListFleets, DescribeFleetAttributes and DescribeBuild, and refreshed on an interval
+	if strings.ToLower(common.GetEnv("FLEET_DISCOVERY_ENABLED", "false")) == "true" {
+		fleetDiscoveryInterval := time.Duration(common.GetEnvInt("FLEET_DISCOVERY_INTERVAL_MS", 60000)) * time.Millisecond
+		sessionDsm.FleetDiscovery = NewFleetDiscovery(sessionDsm.GameLiftClient, fleetDiscoveryInterval)
+	}
+
+	// Sends the regions requested by AGS, renamed with LATENCY_REGION_MAP, as the location order of queue placements
+	// Accepts queue, requested or requested_only. Routes of the routing table can override this with their location_priority field
+	locationPriority, err := parseLocationPriority(common.GetEnv("PLACEMENT_LOCATION_PRIORITY", ""))
+	if err != nil {
+		return nil, err
+	}
+	sessionDsm.LocationPriority = locationPriority
+
+	// Follows the placements started by CreateGameSessionAsync with DescribeGameSessionPlacement and reports their result to AGS,
+	// so dedicated servers don't have to call UpdateDSInformation themselves. Placements GameLift hasn't resolved after
+	// PLACEMENT_TRACKER_MAX_AGE_MS are reported as failed
+	if strings.ToLower(common.GetEnv("PLACEMENT_TRACKER_ENABLED", "false")) == "true" {
+		sessionDsm.PlacementTracker = NewPlacementTracker(
+			time.Duration(common.GetEnvInt("PLACEMENT_POLL_INTERVAL_MS", 5000))*time.Millisecond,
+			time.Duration(common.GetEnvInt("PLACEMENT_TRACKER_MAX_AGE_MS", 900000))*time.Millisecond,
+		)
+	}
+
+	// Accepts the placement events a GameLift queue publishes to SNS on the metrics server, so placements are reported
+	// as soon as they complete instead of on the next poll. Only placements followed by the placement tracker are reported,
+	// and only messages from PLACEMENT_NOTIFICATIONS_TOPIC_ARN sent within PLACEMENT_NOTIFICATIONS_MAX_AGE_MS are accepted
+	if strings.ToLower(common.GetEnv("PLACEMENT_NOTIFICATIONS_ENABLED", "false")) == "true" {
+		if sessionDsm.PlacementTracker == nil {
+			return nil, errors.New("PLACEMENT_NOTIFICATIONS_ENABLED requires PLACEMENT_TRACKER_ENABLED")
+		}
+		topicArn := common.GetEnv("PLACEMENT_NOTIFICATIONS_TOPIC_ARN", "")
+		if topicArn == "" {
+			return nil, errors.New("PLACEMENT_NOTIFICATIONS_ENABLED requires PLACEMENT_NOTIFICATIONS_TOPIC_ARN")
+		}
+		verifier := sns.NewVerifier(nil, nil)
+		verifier.MaxMessageAge = time.Duration(common.GetEnvInt("PLACEMENT_NOTIFICATIONS_MAX_AGE_MS", int(sns.DefaultMaxMessageAge.Milliseconds()))) * time.Millisecond
+		sessionDsm.PlacementNotifications = &PlacementNotifications{
+			TopicArn: topicArn,
+			Verifier: verifier,
+		}
+	}
+
+	return &sessionDsm, nil
+}
+
+// Start runs the background workers of the Session DSM until ctx is done
+func (s *SessionDSM) Start(ctx context.Context) {
+	if s.Routing != nil && s.RoutingReloadInterval > 0 {
+		go s.Routing.Watch(ctx, s.RoutingReloadInterval)
+	}
+
+	if s.CapacityCache != nil {
+		go s.CapacityCache.Run(ctx)
+	}
+
+	if s.FleetDiscovery != nil {
+		go s.FleetDiscovery.Run(ctx)
+	}
+
+	if s.PlacementTracker != nil {
+		go s.runPlacementTracker(ctx)
+	}
+}
+
+// resolveTarget returns the GameLift target for a request from the routing table, falling back to the global overrides
+// Sessions that fall in the canary share of their route are sent to the canary alias or queue
+func (s *SessionDSM) resolveTarget(req *sessiondsm.RequestCreateGameSession, requestPath routing.Path) (routing.Target, routing.Variant) {
+	route, _ := s.Routing.Table().Resolve(routing.Request{
+		Namespace:     req.Namespace,
+		Deployment:    req.Deployment,
+		GameMode:      req.GameMode,
+		ClientVersion: req.ClientVersion,
+	})
+	target, variant := route.Split(req.SessionId, requestPath)
+
+	if target.AliasId == "" {
+		target.AliasId = s.AwsAliasIdOverride
+	}
+
+	if len(target.Locations) == 0 && s.AwsLocationOverride != "" {
+		target.Locations = []string{s.AwsLocationOverride}
+	}
+
+	if target.Queue == "" {
+		target.Queue = s.AwsQueueArnOverride
+	}
+
+	if target.Address == "" {
+		target.Address = string(s.AddressMode)
+	}
+
+	if target.LocationPriority == "" {
+		target.LocationPriority = string(s.LocationPriority)
+	}
+
+	return target, variant
 }
 
 func (s *SessionDSM) CreateGameSession(
@@ -89,91 +425,242 @@ func (s *SessionDSM) CreateGameSession(
 		"game_mode":        req.GameMode,
 	})
 
-	var gameliftResponse *gamelift.CreateGameSessionOutput
-	var err error
+	target, variant := s.resolveTarget(req, routing.PathSync)
+	log = log.WithField("variant", variant)
+	scope.Ctx = withRetryLog(scope.Ctx, log)
 
-	if s.AwsAliasIdOverride != "" {
-		log.Debugf("Using AWS Alias ID override: %v", s.AwsAliasIdOverride)
-		req.Deployment = s.AwsAliasIdOverride
+	if target.AliasId != "" {
+		log.Debugf("Using AWS Alias ID from routing: %v", target.AliasId)
+		req.Deployment = target.AliasId
 	}
 
-	if s.AwsLocationOverride != "" {
-		log.Debugf("Using AWS Location override: %v", s.AwsLocationOverride)
-		req.RequestedRegion = []string{s.AwsLocationOverride}
+	// Routes with discover_fleet send the request to the fleet running the client's build version instead of their alias
+	// Canary sessions never discover their fleet, see routing.Target.Split
+	if target.DiscoverFleet {
+		if s.FleetDiscovery == nil {
+			log.Errorf("Route requires fleet discovery, but fleet discovery is not enabled")
+			return nil, failedPreconditionError("FLEET_DISCOVERY_DISABLED", "route requires fleet discovery, but FLEET_DISCOVERY_ENABLED is not set")
+		}
+
+		discoveredDeployment, err := s.FleetDiscovery.Deployment(req.ClientVersion)
+		if err != nil {
+			log.Errorf("Failed to find a fleet for client version %s: %v", req.ClientVersion, err)
+			return nil, err
+		}
+
+		log.Debugf("Using AWS deployment %s discovered for client version %s", discoveredDeployment, req.ClientVersion)
+		req.Deployment = discoveredDeployment
+	}
+
+	if len(target.Locations) > 0 {
+		log.Debugf("Using AWS Locations from routing: %v", target.Locations)
+		req.RequestedRegion = target.Locations
 	}
 
 	if len(req.RequestedRegion) == 0 {
 		log.Errorf("Requested region is required")
-		return nil, errors.New("need provide requested region")
-	}
-
-	// Store some additional data in session properties. You may add additional values here as needed
-	// These can be read and used by the dedicated server that ends up hosting this session
-	clientVersionKey := "clientVersion"
-	gameModeKey := "gameMode"
-	sessionSecretKey := "sessionSecret"
-	gameProperties := []types.GameProperty{
-		{
-			Key:   &clientVersionKey,
-			Value: &req.ClientVersion,
-		},
-		{
-			Key:   &gameModeKey,
-			Value: &req.GameMode,
-		},
-		{
-			Key:   &sessionSecretKey,
-			Value: &req.Secret,
-		},
-	}
-
-	// Try to create a session in each region, and break when a session is created successfully
-	for _, region := range req.RequestedRegion {
-		maxPlayersI32 := int32(req.MaximumPlayer)
+		return nil, invalidArgumentError("REQUESTED_REGION_REQUIRED", "need provide requested region")
+	}
+
+	// Deployment may name a GameLift alias or fleet, either by its ID or by its ARN
+	gameLiftDeployment := parseDeployment(req.Deployment)
+	if gameLiftDeployment.kind != deploymentAlias && gameLiftDeployment.kind != deploymentFleet {
+		log.Errorf("Deployment %q is not a GameLift alias or fleet", req.Deployment)
+		return nil, invalidArgumentError("INVALID_DEPLOYMENT", "deployment %q is not a GameLift alias or fleet ID or ARN", req.Deployment)
+	}
+
+	var playerIds []string
+	if s.CreatePlayerSessions {
+		var err error
+		playerIds, err = playerIdsForSession(req.SessionData, req.MaximumPlayer, 0)
+		if err != nil {
+			log.Errorf("Failed to read session members: %v", err)
+			return nil, err
+		}
+	}
+
+	// AGS retries a request with the same session ID when it didn't get an answer in time
+	// Hand back the game session created by the earlier request instead of starting a second server
+	if gameSession := s.recordedGameSession(scope.Ctx, log, req.SessionId); gameSession != nil {
+		log.Infof("Returning Game Session %s created by an earlier request", *gameSession.GameSessionId)
+
+		if s.ActivationWait.required(playerIds) {
+			activeGameSession, err := s.waitForActiveGameSession(scope.Ctx, log, gameSession)
+			if err != nil {
+				log.Errorf("Game Session created by an earlier request did not activate: %s", err)
+				return nil, gameLiftError("CreateGameSession", err)
+			}
+			gameSession = activeGameSession
+		}
+
+		return s.createGameSessionResponse(scope.Ctx, log, req, gameSession, playerIds, AddressMode(target.Address))
+	}
+
+	// Use player latencies from the session data, in the same format CreateGameSessionAsync uses, to order the regions
+	if s.LatencyOrdering.Aggregate != "" {
+		playerLatencies, err := s.playerLatencies(req.SessionData)
+		if err != nil {
+			log.WithError(err).Debugf("No player latencies found, keeping requested region order")
+		} else {
+			req.RequestedRegion = s.LatencyOrdering.Order(req.RequestedRegion, playerLatencies, s.LatencyIngester)
+			log.Debugf("Ordered regions by %s player latency: %v", s.LatencyOrdering.Aggregate, req.RequestedRegion)
+		}
+
+		if len(req.RequestedRegion) == 0 {
+			log.Errorf("No requested region is within the latency ceiling of %vms", s.LatencyOrdering.CeilingMs)
+			return nil, failedPreconditionError("NO_REGION_WITHIN_LATENCY_CEILING", "no requested region is within the latency ceiling")
+		}
+	}
+
+	// Don't spend a round trip on locations that are known to have no idle game server processes
+	if s.CapacityCache != nil {
+		req.RequestedRegion = s.CapacityCache.Order(req.Deployment, req.RequestedRegion)
+		log.Debugf("Ordered regions by fleet capacity: %v", req.RequestedRegion)
+
+		if len(req.RequestedRegion) == 0 {
+			log.Errorf("No requested region has idle game server processes")
+			return nil, newStatusError(codes.ResourceExhausted, errorDomainSessionDSM, "NO_IDLE_CAPACITY", "", true, "no requested region has idle game server processes")
+		}
+	}
+
+	settings, err := s.buildGameSessionSettings(req)
+	if err != nil {
+		log.Errorf("Failed to build game session settings: %v", err)
+		return nil, err
+	}
+
+	// Placements have no creator, so this is the only setting CreateGameSessionAsync doesn't share
+	creatorId := s.SessionNaming.creatorId(req.SessionData)
+
+	// Try to create a session in each region, splitting the request deadline between them
+	// The first session that is created successfully wins, and any extra sessions created by hedged attempts are terminated
+	gameSession, err := s.createInRegions(scope.Ctx, log, req.SessionId, req.RequestedRegion, func(ctx context.Context, region string) (*types.GameSession, error) {
+		token := idempotencyToken(req.SessionId, region)
 		createGameSessionInput := &gamelift.CreateGameSessionInput{
-			AliasId:                   &req.Deployment, // Deployment must be either a fully-qualified GameLift Alias ARN or a short Alias ID
-			IdempotencyToken:          &req.SessionId,
-			MaximumPlayerSessionCount: &maxPlayersI32,
+			IdempotencyToken:          &token,
+			MaximumPlayerSessionCount: &settings.maximumPlayers,
 			Location:                  &region,
-			GameProperties:            gameProperties,
+			GameProperties:            settings.gameProperties,
+			GameSessionData:           settings.gameSessionData,
+			Name:                      settings.name,
+			CreatorId:                 creatorId,
 		}
 
-		// Only provide session data if it's not empty
-		if req.SessionData != "" {
-			createGameSessionInput.GameSessionData = &req.SessionData
+		// Sets AliasId or FleetId, depending on which one the deployment names
+		if err := gameLiftDeployment.applyTo(createGameSessionInput); err != nil {
+			return nil, err
 		}
 
-		gameliftResponse, err = s.GameLiftClient.CreateGameSession(ctx, createGameSessionInput)
+		breaker := breakerKey{targetType: string(gameLiftDeployment.kind), target: gameLiftDeployment.id, location: region}
+		if !s.CircuitBreakers.Allow(breaker) {
+			return nil, errCircuitOpen
+		}
+
+		gameliftResponse, err := s.GameLiftClient.CreateGameSession(ctx, createGameSessionInput)
+		s.CircuitBreakers.Record(breaker, err)
 		if err != nil {
-			log.Warnf("Failed to create Game Session in region %s: %s", region, err)
-			continue
+			existingGameSession, ok := s.existingGameSessionForToken(ctx, gameLiftDeployment, region, token, err)
+			if !ok {
+				return nil, err
+			}
+
+			log.Infof("Found Game Session %s created by an earlier request in region %s", *existingGameSession.GameSessionId, region)
+			gameliftResponse = &gamelift.CreateGameSessionOutput{GameSession: existingGameSession}
 		}
 
-		// Session placement succeeded on this region, so we can exit
-		break
-	}
+		if gameliftResponse.GameSession == nil || gameliftResponse.GameSession.GameSessionId == nil {
+			return nil, fmt.Errorf("GameLift returned no game session in region %s", region)
+		}
+
+		// A token that was already used returns the game session of the earlier request, which may have ended since
+		if !isReusableGameSession(gameliftResponse.GameSession) {
+			return nil, fmt.Errorf("game session created by an earlier request in region %s can't be reused, status: %s", region, gameliftResponse.GameSession.Status)
+		}
+
+		if !s.ActivationWait.required(playerIds) {
+			return gameliftResponse.GameSession, nil
+		}
+
+		activeGameSession, err := s.waitForActiveGameSession(ctx, log, gameliftResponse.GameSession)
+		if err != nil {
+			// Out of time, the session may still activate, so createInRegions decides whether it is kept for a retry
+			if ctx.Err() != nil {
+				return gameliftResponse.GameSession, err
+			}
 
+			s.discardGameSession(gameliftResponse.GameSession.GameSessionId, log)
+			return nil, err
+		}
+
+		return activeGameSession, nil
+	})
+	recordGameSessionRequest("CreateGameSession", variant, req.Deployment, err)
 	if err != nil {
 		log.Errorf("Failed to create session: %s", err)
-		return nil, err
+		return nil, gameLiftError("CreateGameSession", err)
+	}
+
+	return s.createGameSessionResponse(scope.Ctx, log, req, gameSession, playerIds, AddressMode(target.Address))
+}
+
+// createGameSessionResponse reserves the player sessions on the game session and returns it to AGS
+// The game session is recorded, so a retry of the same request gets it back
+func (s *SessionDSM) createGameSessionResponse(
+	ctx context.Context,
+	log *logrus.Entry,
+	req *sessiondsm.RequestCreateGameSession,
+	gameSession *types.GameSession,
+	playerIds []string,
+	addressMode AddressMode,
+) (*sessiondsm.ResponseCreateGameSession, error) {
+	if gameSession == nil || gameSession.GameSessionId == nil {
+		log.Errorf("GameLift returned a game session without an ID")
+		return nil, newStatusError(codes.Internal, errorDomainGameLift, "MISSING_GAME_SESSION_ID", "CreateGameSession", false, "GameLift returned a game session without an ID")
+	}
+
+	sessionData := req.SessionData
+	if len(playerIds) > 0 {
+		playerSessions, err := s.createPlayerSessions(ctx, gameSession, playerIds)
+		if err != nil {
+			log.Errorf("Failed to create player sessions: %s", err)
+			s.discardGameSession(gameSession.GameSessionId, log)
+			return nil, gameLiftError("CreatePlayerSessions", err)
+		}
+
+		sessionData, err = withPlayerSessions(sessionData, playerSessions)
+		if err != nil {
+			log.Warnf("Failed to add player sessions to the session data: %v", err)
+		}
+	}
+
+	var port int64
+	if gameSession.Port != nil {
+		port = int64(*gameSession.Port)
+	}
+
+	var location string
+	if gameSession.Location != nil {
+		location = *gameSession.Location
 	}
 
 	response := &sessiondsm.ResponseCreateGameSession{
 		SessionId:     req.SessionId,
 		Namespace:     req.Namespace,
-		SessionData:   req.SessionData,
+		SessionData:   sessionData,
 		ClientVersion: req.ClientVersion,
 		GameMode:      req.GameMode,
 		Source:        constants.GameServerSourceGamelift,
-		Status:        constants.ServerStatusReady,
-		Deployment:    *gameliftResponse.GameSession.GameSessionId, // Set the `Deployment` field to the fully qualified Game Session ARN so that we can terminate the session later
-		Ip:            *gameliftResponse.GameSession.IpAddress,
-		Port:          int64(*gameliftResponse.GameSession.Port),
-		ServerId:      *gameliftResponse.GameSession.GameSessionId, // Set the `ServerId` field to the fully qualified Game Session ARN. This must match what the server provides when connecting to the AccelByte DS Hub
-		Region:        *gameliftResponse.GameSession.Location,
-		CreatedRegion: *gameliftResponse.GameSession.Location,
+		Status:        serverStatusFromGameSession(gameSession.Status),
+		Deployment:    *gameSession.GameSessionId,                               // Set the `Deployment` field to the fully qualified Game Session ARN so that we can terminate the session later
+		Ip:            s.gameSessionAddress(ctx, log, gameSession, addressMode), // The DNS name instead of the IP for fleets with TLS certificates, depending on the address mode
+		Port:          port,
+		ServerId:      *gameSession.GameSessionId, // Set the `ServerId` field to the fully qualified Game Session ARN. This must match what the server provides when connecting to the AccelByte DS Hub
+		Region:        location,
+		CreatedRegion: location,
 	}
 
+	s.GameSessionRecords.Put(req.SessionId, *gameSession.GameSessionId)
+
 	log.Infof("Created session: %v", response)
 	return response, nil
 }
@@ -189,6 +676,14 @@ func (s *SessionDSM) TerminateGameSession(
 		"session_id": req.SessionId,
 		"namespace":  req.Namespace,
 	})
+	scope.Ctx = withRetryLog(scope.Ctx, log)
+
+	if req.SessionId == "" || req.Namespace == "" {
+		err := invalidArgumentError("SESSION_ID_REQUIRED", "session ID and namespace are required")
+		log.Errorf("Invalid terminate request: %v", err)
+		recordSkippedTermination(skippedTerminationInvalidRequest)
+		return failedTermination(req, err)
+	}
 
 	// We need the fully qualified AWS Game Session ARN to make the terminate call, which is not provided in `req`
 	// We query the full session info from AccelByte here to retrieve the ARN in the `deployment` field
@@ -197,31 +692,61 @@ func (s *SessionDSM) TerminateGameSession(
 		SessionID: req.SessionId,
 	})
 	if err != nil {
+		var notFound *game_session.GetGameSessionNotFound
+		if errors.As(err, &notFound) {
+			log.Infof("Session not found, nothing to terminate")
+			recordSkippedTermination(skippedTerminationSessionNotFound)
+			return s.idempotentTermination(req, "session not found")
+		}
+
 		log.Errorf("Failed to get session info while terminating game session: %v", err)
-		return nil, err
+		recordSkippedTermination(skippedTerminationSessionServiceError)
+		return failedTermination(req, sessionServiceError("GetGameSession", err))
+	}
+
+	// Sessions from CreateGameSessionAsync have no game session ARN until their placement is fulfilled,
+	// and sessions whose DS was never assigned have no DS information at all
+	// Their placement, whose ID is the session ID, is stopped instead
+	serverInfo := gameServer(sessionInfo)
+	if serverInfo == nil || strings.TrimSpace(serverInfo.Deployment) == "" {
+		return s.terminatePlacement(scope.Ctx, log, req)
 	}
-	serverInfo := sessionInfo.DSInformation.Server
 
 	log = log.WithFields(logrus.Fields{
 		"server_deployment":   serverInfo.Deployment,
-		"server_region":       serverInfo.Region,
+		"server_region":       aws.ToString(serverInfo.Region),
 		"server_game_version": serverInfo.GameVersion,
-		"server_source":       serverInfo.Source,
+		"server_source":       aws.ToString(serverInfo.Source),
 		"server_provider":     serverInfo.Provider,
-		"server_status":       serverInfo.Status,
+		"server_status":       aws.ToString(serverInfo.Status),
 	})
 
+	// Deployment must be a fully-qualified GameLift Game Session ARN
+	if fleetIdFromGameSessionArn(serverInfo.Deployment) == "" {
+		err = failedPreconditionError("INVALID_GAME_SESSION_ARN", "deployment %q of session %s is not a GameLift game session ARN", serverInfo.Deployment, req.SessionId)
+		log.Errorf("Failed to terminate game session: %v", err)
+		recordSkippedTermination(skippedTerminationInvalidDeployment)
+		return failedTermination(req, err)
+	}
+
 	terminateSessionRequest := &gamelift.TerminateGameSessionInput{
-		GameSessionId:   &serverInfo.Deployment,                         // Deployment must be a fully-qualified GameLift Game Session ARN
+		GameSessionId:   &serverInfo.Deployment,
 		TerminationMode: types.TerminationModeTriggerOnProcessTerminate, // Trigger a normal, graceful shutdown
 	}
 
-	_, err = s.GameLiftClient.TerminateGameSession(ctx, terminateSessionRequest)
+	_, err = s.GameLiftClient.TerminateGameSession(scope.Ctx, terminateSessionRequest)
 	if err != nil {
+		if s.isTerminatedGameSession(scope.Ctx, serverInfo.Deployment, err) {
+			log.Infof("Game session already terminated")
+			return s.idempotentTermination(req, "game session already terminated")
+		}
+
 		log.Errorf("Failed to terminate game session: %v", err)
-		return nil, err
+		return failedTermination(req, gameLiftError("TerminateGameSession", err))
 	}
 
+	s.GameSessionRecords.Delete(req.SessionId)
+
 	response := &sessiondsm.ResponseTerminateGameSession{
 		SessionId: req.SessionId,
 		Namespace: req.Namespace,
@@ -247,39 +772,69 @@ func (s *SessionDSM) CreateGameSessionAsync(
 		"client_version":   req.ClientVersion,
 	})
 
-	if s.AwsQueueArnOverride != "" {
-		log.Debugf("Using AWS Queue ARN override: %v", s.AwsQueueArnOverride)
-		req.Deployment = s.AwsQueueArnOverride
+	target, variant := s.resolveTarget(req, routing.PathAsync)
+	log = log.WithField("variant", variant)
+	scope.Ctx = withRetryLog(scope.Ctx, log)
+
+	if target.Queue != "" {
+		log.Debugf("Using AWS Queue from routing: %v", target.Queue)
+		req.Deployment = target.Queue
 	}
 
 	// GameLift Queues support latency-based matchmaking
 	// If player latencies are provided in SessionData, we try to parse them here
 	// If latency is not supplied, the queue will prioritize based on the location order defined on queue creation
 
-	// This function expects session data to take the following form:
+	// Latencies are read from any of these shapes of session data, see the latency package:
 	// {
 	//   ... // other session data
 	//
-	//   "player_latencies": [
-	//      "player_id_1": {
-	//        "us-west-2": 42.5,
-	//        "us-east-2": 88.23,
-	//      },
-	//      ... // other player latency maps
-	//   ]
+	//   "gamelift_latencies": {"player_id_1": {"us-west-2": 42.5, "us-east-2": 88.23}},
+	//   "player_latencies": [{"player_id_2": {"us-west-2": 42.5}}],
+	//   "members": [{"id": "player_id_3", "attributes": {"latencies": {"us-west-2": 42}}}]
 	// }
-	playerLatencies, err := extractLatencies(req.SessionData)
+	playerLatencies, err := s.playerLatencies(req.SessionData)
 	if err != nil {
 		log.WithError(err).Warnf("failed to parse player QoS data, continuing with session placement for session id %s", req.SessionId)
 	}
 
 	var response sessiondsm.ResponseCreateGameSessionAsync
 
-	maxPlayersI32 := int32(req.MaximumPlayer)
+	// Deployment may be a queue name or ARN. Aliases and fleets can only be used with CreateGameSession
+	queueDeployment := parseDeployment(req.Deployment)
+	if queueDeployment.kind != deploymentQueue {
+		response.Message = fmt.Sprintf("deployment %q for session: %s is a GameLift %s, not a queue", req.Deployment, req.SessionId, queueDeployment.kind)
+		log.Errorf(response.Message)
+		return &response, nil
+	}
+
+	// Player session IDs are reported in the PlacedPlayerSessions of the fulfilled placement
+	var playerIds []string
+	if s.CreatePlayerSessions {
+		playerIds, err = playerIdsForSession(req.SessionData, req.MaximumPlayer, maxDesiredPlayerSessions)
+		if err != nil {
+			response.Message = fmt.Sprintf("failed to read session members for session: %s, Error: %v", req.SessionId, err)
+			log.Errorf(response.Message)
+			return &response, nil
+		}
+	}
+
+	// Servers placed through a queue get the same game properties, session data and name as CreateGameSession sends
+	settings, err := s.buildGameSessionSettings(req)
+	if err != nil {
+		response.Message = fmt.Sprintf("failed to build game session settings for session: %s, Error: %v", req.SessionId, err)
+		log.Errorf(response.Message)
+		return &response, nil
+	}
+
 	createSessionPlacementRequest := &gamelift.StartGameSessionPlacementInput{
 		GameSessionQueueName:      &req.Deployment, // Deployment may be a fully qualified GameLift Queue ARN, or just the queue name
-		MaximumPlayerSessionCount: &maxPlayersI32,
+		MaximumPlayerSessionCount: &settings.maximumPlayers,
 		PlacementId:               &req.SessionId,
+		DesiredPlayerSessions:     desiredPlayerSessions(playerIds),
+		GameProperties:            settings.gameProperties,
+		GameSessionData:           settings.gameSessionData,
+		GameSessionName:           settings.name,
 	}
 
 	// If we have player latencies, add them to the request here
@@ -287,7 +842,23 @@ func (s *SessionDSM) CreateGameSessionAsync(
 		createSessionPlacementRequest.PlayerLatencies = playerLatencies
 	}
 
-	startPlacementResponse, err := s.GameLiftClient.StartGameSessionPlacement(ctx, createSessionPlacementRequest)
+	// Depending on the location priority of the route, the requested regions replace the queue's location order
+	priorityOverride := s.priorityConfigurationOverride(LocationPriority(target.LocationPriority), req.RequestedRegion)
+	if priorityOverride != nil {
+		log.Debugf("Overriding queue location order with %v", priorityOverride.LocationOrder)
+		createSessionPlacementRequest.PriorityConfigurationOverride = priorityOverride
+	}
+
+	breaker := breakerKey{targetType: breakerTargetQueue, target: queueDeployment.id}
+	if !s.CircuitBreakers.Allow(breaker) {
+		response.Message = fmt.Sprintf("skipped gamelift queue session placement for session: %s, circuit breaker is open for queue %s", req.SessionId, req.Deployment)
+		log.Errorf(response.Message)
+		return &response, nil
+	}
+
+	startPlacementResponse, err := s.GameLiftClient.StartGameSessionPlacement(scope.Ctx, createSessionPlacementRequest)
+	s.CircuitBreakers.Record(breaker, err)
+	recordGameSessionRequest("StartGameSessionPlacement", variant, req.Deployment, err)
 	if err != nil {
 		response.Message = fmt.Sprintf("failed to start gamelift queue session placement for session: %s, Error: %v", req.SessionId, err)
 		log.Errorf(response.Message)
@@ -300,44 +871,21 @@ func (s *SessionDSM) CreateGameSessionAsync(
 		return &response, nil
 	}
 
-	log.Infof("Successfully started Game Session Placement: %v", startPlacementResponse)
+	// Only log the placement ID and status, the placement also holds the game properties, including the session secret
+	log.Infof("Successfully started Game Session Placement %s, status: %s", req.SessionId, startPlacementResponse.GameSessionPlacement.Status)
 
 	// The game session placement will be fulfilled asynchronously after this function returns
-	// Developers must call UpdateDSInformation to inform AccelByte that the placement has completed
+	// With the placement tracker enabled, its result is reported to AGS once GameLift resolves it
+	// Otherwise developers must call UpdateDSInformation to inform AccelByte that the placement has completed
 	// See https://docs.aws.amazon.com/gamelift/latest/developerguide/queue-notification.html
+	s.PlacementTracker.track(req.SessionId, trackedPlacement{
+		namespace:     req.Namespace,
+		sessionId:     req.SessionId,
+		clientVersion: req.ClientVersion,
+		gameMode:      req.GameMode,
+		addressMode:   AddressMode(target.Address),
+	})
 
 	response.Success = true
 	return &response, nil
 }
-
-type MatchLatencyData struct {
-	PlayerLatencies map[string]PlayerLatencyData `json:"gamelift_latencies"` // Player ID -> PlayerLatencyData
-}
-type PlayerLatencyData map[string]float32 // e.g. us-west-2 -> 42.5ms
-
-func extractLatencies(sessionData string) ([]types.PlayerLatency, error) {
-	var data MatchLatencyData
-	if err := json.Unmarshal([]byte(sessionData), &data); err != nil {
-		return nil, err
-	}
-
-	var latencies []types.PlayerLatency
-	for playerId, playerLatency := range data.PlayerLatencies {
-		for region, latency := range playerLatency {
-			playerIdCopy := playerId
-			regionCopy := region
-			latencyCopy := latency
-			latencies = append(latencies, types.PlayerLatency{
-				PlayerId:              &playerIdCopy,
-				RegionIdentifier:      &regionCopy,
-				LatencyInMilliseconds: &latencyCopy,
-			})
-		}
-	}
-
-	if len(latencies) == 0 {
-		return nil, fmt.Errorf("failed to find any player latencies in SessionData")
-	}
-
-	return latencies, nil
-}
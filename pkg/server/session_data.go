@@ -0,0 +1,25 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"errors"
+
+	"session-dsm-grpc-plugin/pkg/sessiondata"
+)
+
+// encodeGameSessionData turns the AGS session data into the GameSessionData sent to GameLift
+// Dedicated servers can read it back with sessiondata.Decode
+func (s *SessionDSM) encodeGameSessionData(sessionData string) (string, error) {
+	gameSessionData, err := s.SessionDataEncoder.Encode(sessionData)
+	if errors.Is(err, sessiondata.ErrTooLarge) {
+		return "", invalidArgumentError("GAME_SESSION_DATA_TOO_LARGE", "%v", err)
+	}
+	if err != nil {
+		return "", invalidArgumentError("INVALID_SESSION_DATA", "%v", err)
+	}
+
+	return gameSessionData, nil
+}
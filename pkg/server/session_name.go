@@ -0,0 +1,89 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	sessiondsm "session-dsm-grpc-plugin/pkg/pb"
+)
+
+// maxGameSessionNameLength and maxCreatorIdLength are the longest game session name and creator ID GameLift accepts
+const (
+	maxGameSessionNameLength = 1024
+	maxCreatorIdLength       = 1024
+)
+
+var namePlaceholderPattern = regexp.MustCompile(`\{[^}]*\}`)
+
+var namePlaceholders = map[string]bool{
+	"{namespace}":      true,
+	"{session_id}":     true,
+	"{game_mode}":      true,
+	"{client_version}": true,
+	"{deployment}":     true,
+}
+
+// SessionNaming decides the name and creator ID of the game sessions created in GameLift
+type SessionNaming struct {
+	// Template is the game session name, with {namespace}, {session_id}, {game_mode}, {client_version} and {deployment}
+	// replaced by the values of the request. Leave empty to leave sessions unnamed
+	Template string
+
+	// CreatorFromLeader sets the CreatorId of CreateGameSession to the leader of the AGS session
+	CreatorFromLeader bool
+}
+
+func validateNameTemplate(template string) error {
+	for _, placeholder := range namePlaceholderPattern.FindAllString(template, -1) {
+		if !namePlaceholders[placeholder] {
+			return fmt.Errorf("unknown placeholder %s in game session name template", placeholder)
+		}
+	}
+
+	return nil
+}
+
+// name returns the game session name for the request, or nil when no template is set
+func (n SessionNaming) name(req *sessiondsm.RequestCreateGameSession) *string {
+	if n.Template == "" {
+		return nil
+	}
+
+	name := strings.NewReplacer(
+		"{namespace}", req.Namespace,
+		"{session_id}", req.SessionId,
+		"{game_mode}", req.GameMode,
+		"{client_version}", req.ClientVersion,
+		"{deployment}", req.Deployment,
+	).Replace(n.Template)
+
+	if utf8.RuneCountInString(name) > maxGameSessionNameLength {
+		name = string([]rune(name)[:maxGameSessionNameLength])
+	}
+
+	return &name
+}
+
+// creatorId returns the leader of the AGS session from the session data, or nil when it is not known or not wanted
+// A leader ID too long for GameLift is left out rather than truncated, since a truncated ID would name another player
+func (n SessionNaming) creatorId(sessionData string) *string {
+	if !n.CreatorFromLeader || sessionData == "" {
+		return nil
+	}
+
+	var data struct {
+		LeaderID string `json:"leaderID"`
+	}
+	if err := json.Unmarshal([]byte(sessionData), &data); err != nil || data.LeaderID == "" || utf8.RuneCountInString(data.LeaderID) > maxCreatorIdLength {
+		return nil
+	}
+
+	return &data.LeaderID
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	sessiondsm "session-dsm-grpc-plugin/pkg/pb"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionNamingName(t *testing.T) {
+	req := &sessiondsm.RequestCreateGameSession{
+		Namespace:     "ns",
+		SessionId:     "session-1",
+		GameMode:      "ranked",
+		ClientVersion: "1.2.3",
+		Deployment:    "alias-1234",
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		req      *sessiondsm.RequestCreateGameSession
+		expected *string
+	}{
+		{name: "no template", template: "", req: req, expected: nil},
+		{name: "no placeholders", template: "match", req: req, expected: aws.String("match")},
+		{
+			name:     "all placeholders",
+			template: "{namespace}/{session_id}/{game_mode}/{client_version}/{deployment}",
+			req:      req,
+			expected: aws.String("ns/session-1/ranked/1.2.3/alias-1234"),
+		},
+		{name: "repeated placeholder", template: "{game_mode}-{game_mode}", req: req, expected: aws.String("ranked-ranked")},
+		{name: "empty values", template: "{game_mode}:{session_id}", req: &sessiondsm.RequestCreateGameSession{SessionId: "session-1"}, expected: aws.String(":session-1")},
+		{
+			name:     "truncated to the GameLift limit",
+			template: "{session_id}-" + strings.Repeat("a", maxGameSessionNameLength),
+			req:      req,
+			expected: aws.String(("session-1-" + strings.Repeat("a", maxGameSessionNameLength))[:maxGameSessionNameLength]),
+		},
+		{
+			name:     "truncated by characters, not bytes",
+			template: strings.Repeat("é", maxGameSessionNameLength+1),
+			req:      req,
+			expected: aws.String(strings.Repeat("é", maxGameSessionNameLength)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := SessionNaming{Template: tt.template}.name(tt.req)
+			assert.Equal(t, tt.expected, name)
+			if name != nil {
+				assert.True(t, utf8.ValidString(*name))
+			}
+		})
+	}
+}
+
+func TestSessionNamingCreatorId(t *testing.T) {
+	tests := []struct {
+		name              string
+		creatorFromLeader bool
+		sessionData       string
+		expected          *string
+	}{
+		{name: "leader", creatorFromLeader: true, sessionData: `{"leaderID": "user-1", "members": []}`, expected: aws.String("user-1")},
+		{name: "disabled", creatorFromLeader: false, sessionData: `{"leaderID": "user-1"}`, expected: nil},
+		{name: "no session data", creatorFromLeader: true, sessionData: "", expected: nil},
+		{name: "no leader", creatorFromLeader: true, sessionData: `{"members": []}`, expected: nil},
+		{name: "empty leader", creatorFromLeader: true, sessionData: `{"leaderID": ""}`, expected: nil},
+		{name: "not a JSON object", creatorFromLeader: true, sessionData: `["user-1"]`, expected: nil},
+		{name: "leader ID too long", creatorFromLeader: true, sessionData: `{"leaderID": "` + strings.Repeat("u", maxCreatorIdLength+1) + `"}`, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, SessionNaming{CreatorFromLeader: tt.creatorFromLeader}.creatorId(tt.sessionData))
+		})
+	}
+}
+
+func TestValidateNameTemplate(t *testing.T) {
+	assert.NoError(t, validateNameTemplate("{namespace}-{session_id}-{game_mode}-{client_version}-{deployment}"))
+	assert.NoError(t, validateNameTemplate("plain name"))
+	assert.EqualError(t, validateNameTemplate("{namespace}-{region}"), "unknown placeholder {region} in game session name template")
+}
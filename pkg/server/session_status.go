@@ -0,0 +1,92 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"session-dsm-grpc-plugin/pkg/constants"
+
+	"github.com/aws/aws-sdk-go-v2/service/gamelift"
+	"github.com/aws/aws-sdk-go-v2/service/gamelift/types"
+	"github.com/sirupsen/logrus"
+)
+
+// ActivationWait makes CreateGameSession wait for a new game session to become ACTIVE before returning it to AGS
+type ActivationWait struct {
+	Enabled      bool
+	PollInterval time.Duration
+
+	// Timeout caps the wait when it is shorter than the time left on the region attempt
+	Timeout time.Duration
+}
+
+// required reports whether a new game session has to be ACTIVE before it is returned to AGS
+// Player sessions can only be reserved on an ACTIVE game session, so reserving them always waits
+func (w ActivationWait) required(playerIds []string) bool {
+	return w.Enabled || len(playerIds) > 0
+}
+
+// serverStatusFromGameSession translates a GameLift game session status into an AGS server status
+func serverStatusFromGameSession(status types.GameSessionStatus) string {
+	switch status {
+	case types.GameSessionStatusActive:
+		return constants.ServerStatusReady
+	case types.GameSessionStatusActivating:
+		return constants.ServerStatusCreating
+	case types.GameSessionStatusTerminating:
+		return constants.ServerStatusRemoving
+	case types.GameSessionStatusTerminated, types.GameSessionStatusError:
+		return constants.ServerStatusFailed
+	default:
+		return constants.ServerStatusUnreachable
+	}
+}
+
+// waitForActiveGameSession polls DescribeGameSessions until the game session is ACTIVE, fails, or the wait runs out
+func (s *SessionDSM) waitForActiveGameSession(
+	ctx context.Context,
+	log *logrus.Entry,
+	gameSession *types.GameSession,
+) (*types.GameSession, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.ActivationWait.Timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(s.ActivationWait.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		switch gameSession.Status {
+		case types.GameSessionStatusActive:
+			return gameSession, nil
+		case types.GameSessionStatusActivating:
+			log.Debugf("Game Session %s is still activating", *gameSession.GameSessionId)
+		default:
+			return nil, fmt.Errorf("game session %s did not activate, status: %s", *gameSession.GameSessionId, gameSession.Status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("game session %s did not activate in time: %w", *gameSession.GameSessionId, ctx.Err())
+		case <-ticker.C:
+		}
+
+		describeResponse, err := s.GameLiftClient.DescribeGameSessions(ctx, &gamelift.DescribeGameSessionsInput{
+			GameSessionId: gameSession.GameSessionId,
+		})
+		if err != nil {
+			log.Warnf("Failed to describe Game Session %s while waiting for activation: %v", *gameSession.GameSessionId, err)
+			continue
+		}
+
+		if len(describeResponse.GameSessions) == 0 {
+			return nil, fmt.Errorf("game session %s was not found while waiting for activation", *gameSession.GameSessionId)
+		}
+
+		gameSession = &describeResponse.GameSessions[0]
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"session-dsm-grpc-plugin/pkg/constants"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/gamelift"
+	"github.com/aws/aws-sdk-go-v2/service/gamelift/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDescribeClient answers DescribeGameSessions with the next of the given responses, repeating the last one
+type fakeDescribeClient struct {
+	AmazonGameLiftClient
+
+	responses []describeResponse
+	calls     int
+}
+
+type describeResponse struct {
+	status types.GameSessionStatus
+	err    error
+}
+
+func (c *fakeDescribeClient) DescribeGameSessions(_ context.Context, input *gamelift.DescribeGameSessionsInput, _ ...func(*gamelift.Options)) (*gamelift.DescribeGameSessionsOutput, error) {
+	response := c.responses[min(c.calls, len(c.responses)-1)]
+	c.calls++
+	if response.err != nil {
+		return nil, response.err
+	}
+	if response.status == "" {
+		return &gamelift.DescribeGameSessionsOutput{}, nil
+	}
+
+	return &gamelift.DescribeGameSessionsOutput{
+		GameSessions: []types.GameSession{{GameSessionId: input.GameSessionId, Status: response.status}},
+	}, nil
+}
+
+func TestServerStatusFromGameSession(t *testing.T) {
+	tests := []struct {
+		status   types.GameSessionStatus
+		expected string
+	}{
+		{status: types.GameSessionStatusActive, expected: constants.ServerStatusReady},
+		{status: types.GameSessionStatusActivating, expected: constants.ServerStatusCreating},
+		{status: types.GameSessionStatusTerminating, expected: constants.ServerStatusRemoving},
+		{status: types.GameSessionStatusTerminated, expected: constants.ServerStatusFailed},
+		{status: types.GameSessionStatusError, expected: constants.ServerStatusFailed},
+		{status: types.GameSessionStatus("UNKNOWN"), expected: constants.ServerStatusUnreachable},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			assert.Equal(t, tt.expected, serverStatusFromGameSession(tt.status))
+		})
+	}
+}
+
+func TestWaitForActiveGameSession(t *testing.T) {
+	tests := []struct {
+		name          string
+		initial       types.GameSessionStatus
+		responses     []describeResponse
+		expectedError string
+		expectedCalls int
+	}{
+		{
+			name:          "already active",
+			initial:       types.GameSessionStatusActive,
+			responses:     []describeResponse{{status: types.GameSessionStatusActive}},
+			expectedCalls: 0,
+		},
+		{
+			name:          "activates after polling",
+			initial:       types.GameSessionStatusActivating,
+			responses:     []describeResponse{{status: types.GameSessionStatusActivating}, {status: types.GameSessionStatusActive}},
+			expectedCalls: 2,
+		},
+		{
+			name:          "keeps polling after describe errors",
+			initial:       types.GameSessionStatusActivating,
+			responses:     []describeResponse{{err: errors.New("throttled")}, {status: types.GameSessionStatusActive}},
+			expectedCalls: 2,
+		},
+		{
+			name:          "fails to activate",
+			initial:       types.GameSessionStatusActivating,
+			responses:     []describeResponse{{status: types.GameSessionStatusError}},
+			expectedError: "did not activate, status: ERROR",
+			expectedCalls: 1,
+		},
+		{
+			name:          "not found",
+			initial:       types.GameSessionStatusActivating,
+			responses:     []describeResponse{{}},
+			expectedError: "was not found",
+			expectedCalls: 1,
+		},
+		{
+			name:          "runs out of time",
+			initial:       types.GameSessionStatusActivating,
+			responses:     []describeResponse{{status: types.GameSessionStatusActivating}},
+			expectedError: "did not activate in time",
+			expectedCalls: -1, // Depends on how many polls fit in the timeout
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gameLift := &fakeDescribeClient{responses: tt.responses}
+			s := &SessionDSM{
+				GameLiftClient: gameLift,
+				ActivationWait: ActivationWait{PollInterval: time.Millisecond, Timeout: 50 * time.Millisecond},
+			}
+
+			gameSession, err := s.waitForActiveGameSession(context.Background(), logrus.NewEntry(logrus.New()), &types.GameSession{
+				GameSessionId: aws.String("arn:aws:gamelift:us-west-2::gamesession/fleet-1234/gsess-5678"),
+				Status:        tt.initial,
+			})
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, types.GameSessionStatusActive, gameSession.Status)
+			}
+
+			if tt.expectedCalls >= 0 {
+				assert.Equal(t, tt.expectedCalls, gameLift.calls)
+			}
+		})
+	}
+}
+
+func TestActivationWaitRequired(t *testing.T) {
+	assert.False(t, ActivationWait{}.required(nil))
+	assert.True(t, ActivationWait{Enabled: true}.required(nil))
+	assert.True(t, ActivationWait{}.required([]string{"user-1"}))
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	sessiondsm "session-dsm-grpc-plugin/pkg/pb"
+
+	"github.com/AccelByte/accelbyte-go-sdk/session-sdk/pkg/sessionclientmodels"
+	"github.com/aws/aws-sdk-go-v2/service/gamelift"
+	"github.com/aws/aws-sdk-go-v2/service/gamelift/types"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// gameServer returns the DS of a session, or nil when none was ever assigned
+func gameServer(sessionInfo *sessionclientmodels.ApimodelsGameSessionResponse) *sessionclientmodels.ModelsGameServer {
+	if sessionInfo == nil || sessionInfo.DSInformation == nil {
+		return nil
+	}
+
+	return sessionInfo.DSInformation.Server
+}
+
+// idempotentTermination answers a terminate request with nothing left to terminate as a success, with the reason why
+func (s *SessionDSM) idempotentTermination(req *sessiondsm.RequestTerminateGameSession, reason string) (*sessiondsm.ResponseTerminateGameSession, error) {
+	s.PlacementTracker.untrack(req.SessionId)
+	s.GameSessionRecords.Delete(req.SessionId)
+
+	return &sessiondsm.ResponseTerminateGameSession{
+		SessionId: req.SessionId,
+		Namespace: req.Namespace,
+		Success:   true,
+		Reason:    reason,
+	}, nil
+}
+
+// failedTermination answers a terminate request that failed with Success false, and the ErrorInfo reason and message of err
+// as the Reason. gRPC drops the response of a call that returns an error, so the failure is only reported in the response
+func failedTermination(req *sessiondsm.RequestTerminateGameSession, err error) (*sessiondsm.ResponseTerminateGameSession, error) {
+	st := status.Convert(err)
+	reason := st.Message()
+	for _, detail := range st.Details() {
+		if errorInfo, ok := detail.(*errdetails.ErrorInfo); ok && errorInfo.Reason != "" {
+			reason = errorInfo.Reason + ": " + reason
+			break
+		}
+	}
+
+	return &sessiondsm.ResponseTerminateGameSession{
+		SessionId: req.SessionId,
+		Namespace: req.Namespace,
+		Success:   false,
+		Reason:    reason,
+	}, nil
+}
+
+// isTerminatedGameSession reports whether TerminateGameSession failed because the game session is already gone
+// GameLift rejects terminating a game session that is terminating or terminated, which is confirmed with DescribeGameSessions
+func (s *SessionDSM) isTerminatedGameSession(ctx context.Context, gameSessionArn string, err error) bool {
+	var notFound *types.NotFoundException
+	if errors.As(err, &notFound) {
+		return true
+	}
+
+	var invalidStatus *types.InvalidGameSessionStatusException
+	if !errors.As(err, &invalidStatus) {
+		return false
+	}
+
+	describeResponse, err := s.GameLiftClient.DescribeGameSessions(ctx, &gamelift.DescribeGameSessionsInput{
+		GameSessionId: &gameSessionArn,
+	})
+	if err != nil || describeResponse == nil || len(describeResponse.GameSessions) == 0 {
+		return false
+	}
+
+	switch describeResponse.GameSessions[0].Status {
+	case types.GameSessionStatusTerminating, types.GameSessionStatusTerminated:
+		return true
+	default:
+		return false
+	}
+}
+
+// terminatePlacement stops the placement of a session that AGS terminates before GameLift placed it, so no server
+// starts later with nobody to serve. If the placement was fulfilled in the meantime, its game session is terminated instead
+func (s *SessionDSM) terminatePlacement(
+	ctx context.Context,
+	log *logrus.Entry,
+	req *sessiondsm.RequestTerminateGameSession,
+) (*sessiondsm.ResponseTerminateGameSession, error) {
+	log = log.WithField("placement_id", req.SessionId)
+
+	placement, err := s.stopPlacement(ctx, req.SessionId)
+	if status.Code(err) == codes.NotFound {
+		log.Infof("No game session or placement found for the session, nothing to terminate")
+		return s.idempotentTermination(req, "no game session or placement found")
+	}
+	if err != nil {
+		log.Errorf("Failed to stop game session placement: %v", err)
+		return failedTermination(req, err)
+	}
+
+	var reason string
+	switch placement.Status {
+	case types.GameSessionPlacementStateCancelled:
+		log.Infof("Stopped pending game session placement")
+		reason = "placement stopped"
+	case types.GameSessionPlacementStateFulfilled:
+		if placement.GameSessionArn == nil || *placement.GameSessionArn == "" {
+			err = failedPreconditionError("MISSING_GAME_SESSION", "placement %s was fulfilled without a game session", req.SessionId)
+			log.Errorf("Failed to terminate game session of fulfilled placement: %v", err)
+			return failedTermination(req, err)
+		}
+
+		log = log.WithField("game_session_arn", *placement.GameSessionArn)
+		_, err = s.GameLiftClient.TerminateGameSession(ctx, &gamelift.TerminateGameSessionInput{
+			GameSessionId:   placement.GameSessionArn,
+			TerminationMode: types.TerminationModeTriggerOnProcessTerminate, // Trigger a normal, graceful shutdown
+		})
+		if err != nil && !s.isTerminatedGameSession(ctx, *placement.GameSessionArn, err) {
+			log.Errorf("Failed to terminate game session of fulfilled placement: %v", err)
+			return failedTermination(req, gameLiftError("TerminateGameSession", err))
+		}
+		log.Infof("Terminated game session of fulfilled placement")
+	default:
+		log.Infof("Game session placement already ended with status %s", placement.Status)
+		reason = fmt.Sprintf("placement already ended with status %s", placement.Status)
+	}
+
+	// The placement is resolved, so the tracker must not report it to AGS anymore
+	s.PlacementTracker.untrack(req.SessionId)
+	s.GameSessionRecords.Delete(req.SessionId)
+
+	return &sessiondsm.ResponseTerminateGameSession{
+		SessionId: req.SessionId,
+		Namespace: req.Namespace,
+		Success:   true,
+		Reason:    reason,
+	}, nil
+}
+
+// stopPlacement stops a pending placement, or describes it when it is no longer pending
+func (s *SessionDSM) stopPlacement(ctx context.Context, placementId string) (*types.GameSessionPlacement, error) {
+	stopResponse, err := s.GameLiftClient.StopGameSessionPlacement(ctx, &gamelift.StopGameSessionPlacementInput{
+		PlacementId: &placementId,
+	})
+	if err == nil && stopResponse != nil && stopResponse.GameSessionPlacement != nil {
+		return stopResponse.GameSessionPlacement, nil
+	}
+
+	// GameLift only stops PENDING placements and rejects the others
+	var invalidRequest *types.InvalidRequestException
+	if err != nil && !errors.As(err, &invalidRequest) {
+		return nil, gameLiftError("StopGameSessionPlacement", err)
+	}
+
+	describeResponse, err := s.GameLiftClient.DescribeGameSessionPlacement(ctx, &gamelift.DescribeGameSessionPlacementInput{
+		PlacementId: &placementId,
+	})
+	if err != nil {
+		return nil, gameLiftError("DescribeGameSessionPlacement", err)
+	}
+
+	if describeResponse == nil || describeResponse.GameSessionPlacement == nil {
+		return nil, newStatusError(codes.NotFound, errorDomainGameLift, "NOT_FOUND", "DescribeGameSessionPlacement", false, "game session placement not found")
+	}
+
+	return describeResponse.GameSessionPlacement, nil
+}
@@ -0,0 +1,210 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sessiondsm "session-dsm-grpc-plugin/pkg/pb"
+
+	"github.com/AccelByte/accelbyte-go-sdk/session-sdk/pkg/sessionclient/game_session"
+	"github.com/AccelByte/accelbyte-go-sdk/session-sdk/pkg/sessionclientmodels"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/gamelift"
+	"github.com/aws/aws-sdk-go-v2/service/gamelift/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSessionClient struct {
+	session *sessionclientmodels.ApimodelsGameSessionResponse
+	err     error
+}
+
+func (c *fakeSessionClient) GetGameSessionShort(_ *game_session.GetGameSessionParams) (*sessionclientmodels.ApimodelsGameSessionResponse, error) {
+	return c.session, c.err
+}
+
+type fakeStopPlacementClient struct {
+	fakePlacementClient
+
+	stopped      []string
+	terminated   []string
+	terminateErr error
+}
+
+func (c *fakeStopPlacementClient) StopGameSessionPlacement(_ context.Context, input *gamelift.StopGameSessionPlacementInput, _ ...func(*gamelift.Options)) (*gamelift.StopGameSessionPlacementOutput, error) {
+	placement, ok := c.placements[*input.PlacementId]
+	if !ok {
+		return nil, &types.NotFoundException{Message: aws.String("placement not found")}
+	}
+	if placement.Status != types.GameSessionPlacementStatePending {
+		return nil, &types.InvalidRequestException{Message: aws.String("placement is not pending")}
+	}
+
+	c.stopped = append(c.stopped, *input.PlacementId)
+	placement.Status = types.GameSessionPlacementStateCancelled
+
+	return &gamelift.StopGameSessionPlacementOutput{GameSessionPlacement: &placement}, nil
+}
+
+func (c *fakeStopPlacementClient) TerminateGameSession(_ context.Context, input *gamelift.TerminateGameSessionInput, _ ...func(*gamelift.Options)) (*gamelift.TerminateGameSessionOutput, error) {
+	if c.terminateErr != nil {
+		return nil, c.terminateErr
+	}
+	c.terminated = append(c.terminated, *input.GameSessionId)
+
+	return &gamelift.TerminateGameSessionOutput{}, nil
+}
+
+func TestTerminateGameSessionStopsPendingPlacement(t *testing.T) {
+	gameLift := &fakeStopPlacementClient{fakePlacementClient: fakePlacementClient{placements: map[string]types.GameSessionPlacement{
+		"session-1": {PlacementId: aws.String("session-1"), Status: types.GameSessionPlacementStatePending},
+	}}}
+	s := &SessionDSM{
+		GameLiftClient:   gameLift,
+		SessionClient:    &fakeSessionClient{session: &sessionclientmodels.ApimodelsGameSessionResponse{DSInformation: &sessionclientmodels.ApimodelsDSInformationResponse{}}},
+		PlacementTracker: NewPlacementTracker(time.Second, time.Hour),
+	}
+	s.PlacementTracker.track("session-1", trackedPlacement{namespace: "ns", sessionId: "session-1"})
+
+	response, err := s.TerminateGameSession(context.Background(), &sessiondsm.RequestTerminateGameSession{SessionId: "session-1", Namespace: "ns"})
+	require.NoError(t, err)
+	assert.True(t, response.Success)
+	assert.Equal(t, []string{"session-1"}, gameLift.stopped)
+	assert.Empty(t, gameLift.terminated)
+
+	_, tracked := s.PlacementTracker.get("session-1")
+	assert.False(t, tracked)
+}
+
+func TestTerminateGameSessionTerminatesFulfilledPlacement(t *testing.T) {
+	gameSessionArn := "arn:aws:gamelift:us-west-2::gamesession/fleet-1234/gsess-5678"
+	gameLift := &fakeStopPlacementClient{fakePlacementClient: fakePlacementClient{placements: map[string]types.GameSessionPlacement{
+		"session-1": {
+			PlacementId:    aws.String("session-1"),
+			Status:         types.GameSessionPlacementStateFulfilled,
+			GameSessionArn: aws.String(gameSessionArn),
+		},
+	}}}
+	s := &SessionDSM{
+		GameLiftClient: gameLift,
+		SessionClient: &fakeSessionClient{session: &sessionclientmodels.ApimodelsGameSessionResponse{DSInformation: &sessionclientmodels.ApimodelsDSInformationResponse{
+			Server: &sessionclientmodels.ModelsGameServer{},
+		}}},
+	}
+
+	response, err := s.TerminateGameSession(context.Background(), &sessiondsm.RequestTerminateGameSession{SessionId: "session-1", Namespace: "ns"})
+	require.NoError(t, err)
+	assert.True(t, response.Success)
+	assert.Empty(t, gameLift.stopped)
+	assert.Equal(t, []string{gameSessionArn}, gameLift.terminated)
+}
+
+func TestTerminateGameSessionIsIdempotent(t *testing.T) {
+	gameSessionArn := "arn:aws:gamelift:us-west-2::gamesession/fleet-1234/gsess-5678"
+	tests := []struct {
+		name          string
+		sessionClient *fakeSessionClient
+		terminateErr  error
+		reason        string
+	}{
+		{
+			name:          "Session not found",
+			sessionClient: &fakeSessionClient{err: &game_session.GetGameSessionNotFound{}},
+			reason:        "session not found",
+		},
+		{
+			name:          "No DS information",
+			sessionClient: &fakeSessionClient{session: &sessionclientmodels.ApimodelsGameSessionResponse{}},
+			reason:        "no game session or placement found",
+		},
+		{
+			name: "Already terminated",
+			sessionClient: &fakeSessionClient{session: &sessionclientmodels.ApimodelsGameSessionResponse{DSInformation: &sessionclientmodels.ApimodelsDSInformationResponse{
+				Server: &sessionclientmodels.ModelsGameServer{Deployment: gameSessionArn},
+			}}},
+			terminateErr: &types.NotFoundException{Message: aws.String("game session not found")},
+			reason:       "game session already terminated",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &SessionDSM{
+				GameLiftClient: &fakeStopPlacementClient{terminateErr: tt.terminateErr},
+				SessionClient:  tt.sessionClient,
+			}
+
+			response, err := s.TerminateGameSession(context.Background(), &sessiondsm.RequestTerminateGameSession{SessionId: "session-1", Namespace: "ns"})
+			require.NoError(t, err)
+			assert.True(t, response.Success)
+			assert.Equal(t, tt.reason, response.Reason)
+		})
+	}
+}
+
+func TestTerminateGameSessionValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *sessiondsm.RequestTerminateGameSession
+		session *sessionclientmodels.ApimodelsGameSessionResponse
+		reason  string
+		skipped string
+	}{
+		{
+			name:    "Missing session ID",
+			req:     &sessiondsm.RequestTerminateGameSession{Namespace: "ns"},
+			reason:  "SESSION_ID_REQUIRED: session ID and namespace are required",
+			skipped: skippedTerminationInvalidRequest,
+		},
+		{
+			name: "Deployment is not a game session ARN",
+			req:  &sessiondsm.RequestTerminateGameSession{SessionId: "session-1", Namespace: "ns"},
+			session: &sessionclientmodels.ApimodelsGameSessionResponse{DSInformation: &sessionclientmodels.ApimodelsDSInformationResponse{
+				Server: &sessionclientmodels.ModelsGameServer{Deployment: "fleet-1234"},
+			}},
+			reason:  `INVALID_GAME_SESSION_ARN: deployment "fleet-1234" of session session-1 is not a GameLift game session ARN`,
+			skipped: skippedTerminationInvalidDeployment,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gameLift := &fakeStopPlacementClient{}
+			s := &SessionDSM{
+				GameLiftClient: gameLift,
+				SessionClient:  &fakeSessionClient{session: tt.session},
+			}
+			skippedBefore := testutil.ToFloat64(skippedTerminations.WithLabelValues(tt.skipped))
+
+			response, err := s.TerminateGameSession(context.Background(), tt.req)
+			require.NoError(t, err)
+			assert.False(t, response.Success)
+			assert.Equal(t, tt.reason, response.Reason)
+			assert.Empty(t, gameLift.terminated)
+			assert.Equal(t, skippedBefore+1, testutil.ToFloat64(skippedTerminations.WithLabelValues(tt.skipped)))
+		})
+	}
+}
+
+func TestTerminateGameSessionReportsGameLiftFailure(t *testing.T) {
+	gameSessionArn := "arn:aws:gamelift:us-west-2::gamesession/fleet-1234/gsess-5678"
+	s := &SessionDSM{
+		GameLiftClient: &fakeStopPlacementClient{terminateErr: &types.LimitExceededException{Message: aws.String("too many terminations")}},
+		SessionClient: &fakeSessionClient{session: &sessionclientmodels.ApimodelsGameSessionResponse{DSInformation: &sessionclientmodels.ApimodelsDSInformationResponse{
+			Server: &sessionclientmodels.ModelsGameServer{Deployment: gameSessionArn},
+		}}},
+	}
+
+	response, err := s.TerminateGameSession(context.Background(), &sessiondsm.RequestTerminateGameSession{SessionId: "session-1", Namespace: "ns"})
+	require.NoError(t, err)
+	assert.False(t, response.Success)
+	assert.Contains(t, response.Reason, "LIMIT_EXCEEDED: ")
+	assert.Contains(t, response.Reason, "too many terminations")
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+// Package sessiondata encodes AGS session data into GameLift game session data, and decodes it again
+// Dedicated servers can import this package and call Decode on the GameSessionData they receive from GameLift
+package sessiondata
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxLength is the largest GameSessionData GameLift accepts, in characters
+const MaxLength = 262144
+
+// GzipPrefix marks game session data that has been gzipped and base64 encoded
+const GzipPrefix = "gzip+base64:"
+
+// Compression decides when session data is compressed
+type Compression string
+
+const (
+	CompressionOff  Compression = ""
+	CompressionGzip Compression = "gzip"
+	CompressionAuto Compression = "auto"
+)
+
+// ParseCompression reads a compression mode from its configuration value
+func ParseCompression(value string) (Compression, error) {
+	compression := Compression(strings.ToLower(value))
+	switch compression {
+	case CompressionOff, CompressionGzip, CompressionAuto:
+		return compression, nil
+	case "off":
+		return CompressionOff, nil
+	default:
+		return "", fmt.Errorf("unknown session data compression %q, expected off, gzip or auto", value)
+	}
+}
+
+// ErrTooLarge is returned when the encoded session data is still longer than MaxLength
+var ErrTooLarge = errors.New("session data is too large for GameLift")
+
+// Encoder turns AGS session data into GameLift game session data
+// The zero value passes session data through unchanged, and only checks its length
+type Encoder struct {
+	// Allowlist keeps only these top-level fields of the session data
+	// Leave empty to keep every field
+	Allowlist []string
+
+	// Compression gzips and base64 encodes the data, either always or only when it doesn't fit otherwise
+	Compression Compression
+}
+
+// Encode applies the allowlist and compression to the session data
+// It fails with an error wrapping ErrTooLarge when the result is longer than MaxLength
+func (e Encoder) Encode(sessionData string) (string, error) {
+	if sessionData == "" {
+		return "", nil
+	}
+
+	encoded := sessionData
+	if len(e.Allowlist) > 0 {
+		filtered, err := filterFields(sessionData, e.Allowlist)
+		if err != nil {
+			return "", err
+		}
+		encoded = filtered
+	}
+
+	if e.Compression == CompressionGzip || (e.Compression == CompressionAuto && utf8.RuneCountInString(encoded) > MaxLength) {
+		compressed, err := compress(encoded)
+		if err != nil {
+			return "", err
+		}
+		encoded = compressed
+	}
+
+	if length := utf8.RuneCountInString(encoded); length > MaxLength {
+		return "", fmt.Errorf("%w: %d characters after encoding, the limit is %d", ErrTooLarge, length, MaxLength)
+	}
+
+	return encoded, nil
+}
+
+// Decode returns the session data from GameLift game session data created by Encode
+// Data that was not compressed is returned as is
+func Decode(gameSessionData string) ([]byte, error) {
+	if !strings.HasPrefix(gameSessionData, GzipPrefix) {
+		return []byte(gameSessionData), nil
+	}
+
+	compressed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(gameSessionData, GzipPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("failed to base64 decode session data: %w", err)
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress session data: %w", err)
+	}
+	defer reader.Close()
+
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress session data: %w", err)
+	}
+
+	return decompressed, nil
+}
+
+// DecodeJSON decodes GameLift game session data created by Encode into v
+func DecodeJSON(gameSessionData string, v interface{}) error {
+	decoded, err := Decode(gameSessionData)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(decoded, v)
+}
+
+func filterFields(sessionData string, allowlist []string) (string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(sessionData), &fields); err != nil {
+		return "", fmt.Errorf("session data must be a JSON object to apply the allowlist: %w", err)
+	}
+
+	filtered := make(map[string]json.RawMessage, len(allowlist))
+	for _, field := range allowlist {
+		if value, ok := fields[field]; ok {
+			filtered[field] = value
+		}
+	}
+
+	content, err := json.Marshal(filtered)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode filtered session data: %w", err)
+	}
+
+	return string(content), nil
+}
+
+func compress(sessionData string) (string, error) {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write([]byte(sessionData)); err != nil {
+		return "", fmt.Errorf("failed to compress session data: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("failed to compress session data: %w", err)
+	}
+
+	return GzipPrefix + base64.StdEncoding.EncodeToString(buffer.Bytes()), nil
+}
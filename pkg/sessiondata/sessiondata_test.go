@@ -0,0 +1,90 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package sessiondata
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSessionData = `{"teams":[{"user_ids":["a","b"]}],"match_attributes":{"map":"dust"},"debug":{"trace":"x"}}`
+
+func TestEncodeRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoder Encoder
+		want    string
+	}{
+		{
+			name:    "Pass through",
+			encoder: Encoder{},
+			want:    testSessionData,
+		},
+		{
+			name:    "Allowlist",
+			encoder: Encoder{Allowlist: []string{"teams", "match_attributes", "missing"}},
+			want:    `{"match_attributes":{"map":"dust"},"teams":[{"user_ids":["a","b"]}]}`,
+		},
+		{
+			name:    "Gzip",
+			encoder: Encoder{Compression: CompressionGzip},
+			want:    testSessionData,
+		},
+		{
+			name:    "Auto leaves small data uncompressed",
+			encoder: Encoder{Compression: CompressionAuto},
+			want:    testSessionData,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := tt.encoder.Encode(testSessionData)
+			require.NoError(t, err)
+			assert.Equal(t, tt.encoder.Compression == CompressionGzip, strings.HasPrefix(encoded, GzipPrefix))
+
+			decoded, err := Decode(encoded)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(decoded))
+		})
+	}
+}
+
+func TestEncodeTooLarge(t *testing.T) {
+	// Repetitive data compresses well enough to fit
+	repetitive := fmt.Sprintf(`{"padding":%q}`, strings.Repeat("a", MaxLength))
+
+	_, err := Encoder{}.Encode(repetitive)
+	assert.ErrorIs(t, err, ErrTooLarge)
+
+	encoded, err := Encoder{Compression: CompressionAuto}.Encode(repetitive)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encoded, GzipPrefix))
+
+	// Random data does not
+	random := make([]byte, MaxLength)
+	_, err = rand.Read(random)
+	require.NoError(t, err)
+
+	_, err = Encoder{Compression: CompressionAuto}.Encode(fmt.Sprintf(`{"padding":%q}`, hex.EncodeToString(random)))
+	assert.ErrorIs(t, err, ErrTooLarge)
+}
+
+func TestDecodeJSON(t *testing.T) {
+	encoded, err := Encoder{Compression: CompressionGzip}.Encode(testSessionData)
+	require.NoError(t, err)
+
+	var data struct {
+		MatchAttributes map[string]string `json:"match_attributes"`
+	}
+	require.NoError(t, DecodeJSON(encoded, &data))
+	assert.Equal(t, "dust", data.MatchAttributes["map"])
+}
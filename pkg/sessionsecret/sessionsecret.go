@@ -0,0 +1,193 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+// Package sessionsecret protects the AGS session secret before it is sent to GameLift as a game property
+// Dedicated servers can import this package to verify or decrypt the value they receive
+package sessionsecret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Mode decides what is sent to GameLift in place of the session secret
+type Mode string
+
+const (
+	// ModePlaintext sends the secret as is
+	ModePlaintext Mode = ""
+
+	// ModeHMAC sends the hex encoded HMAC-SHA256 of the secret
+	// The server can verify a secret it is given, but can't recover it
+	ModeHMAC Mode = "hmac"
+
+	// ModeAES sends the secret encrypted with AES-GCM, as base64 of the nonce followed by the ciphertext
+	ModeAES Mode = "aes"
+)
+
+const (
+	// maxPropertyValueLength is the longest value GameLift accepts for a game property
+	maxPropertyValueLength = 96
+
+	// aesNonceSize and aesTagSize are the sizes added to every secret encrypted with AES-GCM
+	aesNonceSize = 12
+	aesTagSize   = 16
+
+	// MaxAESSecretLength is the longest secret whose ModeAES value still fits a game property, in bytes
+	// Base64 turns every 3 bytes of the nonce, ciphertext and tag into 4 characters
+	MaxAESSecretLength = maxPropertyValueLength/4*3 - aesNonceSize - aesTagSize
+)
+
+// ErrSecretTooLong is returned by Protect when the protected secret would not fit a game property
+var ErrSecretTooLong = fmt.Errorf("session secret is longer than the %d bytes that fit a game property when encrypted", MaxAESSecretLength)
+
+// ParseMode reads a protection mode from its configuration value
+func ParseMode(value string) (Mode, error) {
+	switch mode := Mode(strings.ToLower(value)); mode {
+	case ModePlaintext, ModeHMAC, ModeAES:
+		return mode, nil
+	case "plaintext":
+		return ModePlaintext, nil
+	default:
+		return "", fmt.Errorf("unknown session secret mode %q, expected plaintext, hmac or aes", value)
+	}
+}
+
+// LoadKey reads a base64 encoded key from a file, e.g. one mounted from a Kubernetes secret
+func LoadKey(filePath string) ([]byte, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session secret key %s: %w", filePath, err)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil {
+		return nil, fmt.Errorf("session secret key %s is not valid base64: %w", filePath, err)
+	}
+
+	return key, nil
+}
+
+// Protector turns session secrets into the value sent to GameLift
+// A nil Protector sends secrets as is
+type Protector struct {
+	mode Mode
+	key  []byte
+	aead cipher.AEAD
+}
+
+// NewProtector returns a Protector for the mode, or nil for ModePlaintext
+// AES keys must be 16, 24 or 32 bytes long, HMAC keys at least 16
+func NewProtector(mode Mode, key []byte) (*Protector, error) {
+	if mode == ModePlaintext {
+		return nil, nil
+	}
+
+	protector := &Protector{mode: mode, key: key}
+	switch mode {
+	case ModeHMAC:
+		if len(key) < 16 {
+			return nil, errors.New("session secret HMAC key must be at least 16 bytes")
+		}
+	case ModeAES:
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		protector.aead = aead
+	default:
+		return nil, fmt.Errorf("unknown session secret mode %q", mode)
+	}
+
+	return protector, nil
+}
+
+// Protect returns the value to send to GameLift in place of the secret
+// In ModeAES, secrets longer than MaxAESSecretLength fail with ErrSecretTooLong
+func (p *Protector) Protect(secret string) (string, error) {
+	if p == nil {
+		return secret, nil
+	}
+
+	switch p.mode {
+	case ModeHMAC:
+		return Sign(p.key, secret), nil
+	case ModeAES:
+		if len(secret) > MaxAESSecretLength {
+			return "", ErrSecretTooLong
+		}
+
+		nonce := make([]byte, p.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return "", fmt.Errorf("failed to generate nonce: %w", err)
+		}
+
+		return base64.StdEncoding.EncodeToString(p.aead.Seal(nonce, nonce, []byte(secret), nil)), nil
+	default:
+		return secret, nil
+	}
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the secret
+func Sign(key []byte, secret string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(secret))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether the secret matches a value created in ModeHMAC, in constant time
+func Verify(key []byte, secret, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(secret))
+
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// Decrypt returns the secret from a value created in ModeAES
+func Decrypt(key []byte, encrypted string) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("encrypted session secret is not valid base64: %w", err)
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("encrypted session secret is too short")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	secret, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt session secret: %w", err)
+	}
+
+	return string(secret), nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid session secret AES key: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
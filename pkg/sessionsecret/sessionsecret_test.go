@@ -0,0 +1,82 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package sessionsecret
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "3f1c9a0e-5d2b-4f7a-9c61-8e0b2d4a6f13"
+
+func TestHMAC(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	protector, err := NewProtector(ModeHMAC, key)
+	require.NoError(t, err)
+
+	protected, err := protector.Protect(testSecret)
+	require.NoError(t, err)
+	assert.NotContains(t, protected, testSecret)
+	assert.True(t, Verify(key, testSecret, protected))
+	assert.False(t, Verify(key, "other-secret", protected))
+	assert.False(t, Verify(bytes.Repeat([]byte{2}, 32), testSecret, protected))
+
+	_, err = NewProtector(ModeHMAC, []byte("short"))
+	assert.Error(t, err)
+}
+
+func TestAES(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	protector, err := NewProtector(ModeAES, key)
+	require.NoError(t, err)
+
+	protected, err := protector.Protect(testSecret)
+	require.NoError(t, err)
+	assert.NotContains(t, protected, testSecret)
+	// Must fit in a GameLift game property value
+	assert.LessOrEqual(t, len(protected), 96)
+
+	secret, err := Decrypt(key, protected)
+	require.NoError(t, err)
+	assert.Equal(t, testSecret, secret)
+
+	_, err = Decrypt(bytes.Repeat([]byte{2}, 32), protected)
+	assert.Error(t, err)
+
+	_, err = NewProtector(ModeAES, []byte("not-an-aes-key"))
+	assert.Error(t, err)
+}
+
+func TestAESSecretLength(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	protector, err := NewProtector(ModeAES, key)
+	require.NoError(t, err)
+	require.Equal(t, 44, MaxAESSecretLength)
+
+	longest := strings.Repeat("s", MaxAESSecretLength)
+	protected, err := protector.Protect(longest)
+	require.NoError(t, err)
+	assert.Len(t, protected, 96)
+
+	secret, err := Decrypt(key, protected)
+	require.NoError(t, err)
+	assert.Equal(t, longest, secret)
+
+	_, err = protector.Protect(longest + "s")
+	assert.ErrorIs(t, err, ErrSecretTooLong)
+}
+
+func TestPlaintext(t *testing.T) {
+	protector, err := NewProtector(ModePlaintext, nil)
+	require.NoError(t, err)
+
+	protected, err := protector.Protect(testSecret)
+	require.NoError(t, err)
+	assert.Equal(t, testSecret, protected)
+}
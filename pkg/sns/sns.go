@@ -0,0 +1,300 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+// Package sns verifies the messages Amazon SNS delivers to HTTP(S) subscriptions, and confirms those subscriptions
+// See https://docs.aws.amazon.com/sns/latest/dg/sns-verify-signature-of-message.html
+package sns
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	_ "crypto/sha1" //nolint:gosec // SNS signs messages with SHA1 when the topic uses SignatureVersion 1
+	_ "crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message types delivered by SNS
+const (
+	TypeSubscriptionConfirmation = "SubscriptionConfirmation"
+	TypeNotification             = "Notification"
+	TypeUnsubscribeConfirmation  = "UnsubscribeConfirmation"
+)
+
+// maxCertificateSize caps the size of a signing certificate that is downloaded
+const maxCertificateSize = 64 * 1024
+
+// DefaultMaxMessageAge is the oldest message accepted when Verifier.MaxMessageAge is not set
+// SNS keeps the Timestamp of a message when it delivers it again, so this must cover the retries of the delivery policy
+const DefaultMaxMessageAge = time.Hour
+
+// maxClockSkew is how far in the future the Timestamp of a message may be
+const maxClockSkew = 5 * time.Minute
+
+// DefaultHosts matches the hosts SNS serves signing certificates and subscription URLs from
+var DefaultHosts = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+// Message is the JSON document SNS posts to an HTTP(S) subscription
+type Message struct {
+	Type             string `json:"Type"`
+	MessageId        string `json:"MessageId"`
+	Token            string `json:"Token,omitempty"`
+	TopicArn         string `json:"TopicArn"`
+	Subject          string `json:"Subject,omitempty"`
+	Message          string `json:"Message"`
+	Timestamp        string `json:"Timestamp"`
+	SignatureVersion string `json:"SignatureVersion"`
+	Signature        string `json:"Signature"`
+	SigningCertURL   string `json:"SigningCertURL"`
+	SubscribeURL     string `json:"SubscribeURL,omitempty"`
+	UnsubscribeURL   string `json:"UnsubscribeURL,omitempty"`
+}
+
+// StringToSign returns the canonical form of the message that SNS signs
+func (m *Message) StringToSign() (string, error) {
+	var fields [][2]string
+	switch m.Type {
+	case TypeNotification:
+		fields = append(fields, [2]string{"Message", m.Message}, [2]string{"MessageId", m.MessageId})
+		if m.Subject != "" {
+			fields = append(fields, [2]string{"Subject", m.Subject})
+		}
+		fields = append(fields, [2]string{"Timestamp", m.Timestamp}, [2]string{"TopicArn", m.TopicArn}, [2]string{"Type", m.Type})
+	case TypeSubscriptionConfirmation, TypeUnsubscribeConfirmation:
+		fields = append(fields,
+			[2]string{"Message", m.Message},
+			[2]string{"MessageId", m.MessageId},
+			[2]string{"SubscribeURL", m.SubscribeURL},
+			[2]string{"Timestamp", m.Timestamp},
+			[2]string{"Token", m.Token},
+			[2]string{"TopicArn", m.TopicArn},
+			[2]string{"Type", m.Type},
+		)
+	default:
+		return "", fmt.Errorf("unknown SNS message type %q", m.Type)
+	}
+
+	var builder strings.Builder
+	for _, field := range fields {
+		builder.WriteString(field[0])
+		builder.WriteString("\n")
+		builder.WriteString(field[1])
+		builder.WriteString("\n")
+	}
+
+	return builder.String(), nil
+}
+
+// Verifier checks SNS message signatures against the signing certificates published by SNS
+// Certificates are only downloaded over HTTPS from the allowed hosts, and are cached by URL
+type Verifier struct {
+	// MaxMessageAge rejects messages whose Timestamp is older than this, so captured messages can't be replayed
+	// Zero uses DefaultMaxMessageAge
+	MaxMessageAge time.Duration
+
+	client *http.Client
+	hosts  *regexp.Regexp
+
+	mu           sync.Mutex
+	certificates map[string]*x509.Certificate
+	now          func() time.Time
+}
+
+// NewVerifier returns a verifier that downloads certificates with the client from hosts matching the pattern
+// A nil client uses a client with a 10 second timeout, and a nil pattern uses DefaultHosts
+func NewVerifier(client *http.Client, hosts *regexp.Regexp) *Verifier {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if hosts == nil {
+		hosts = DefaultHosts
+	}
+
+	return &Verifier{
+		client:       client,
+		hosts:        hosts,
+		certificates: make(map[string]*x509.Certificate),
+		now:          time.Now,
+	}
+}
+
+// Verify checks the message was signed by SNS, and is recent
+func (v *Verifier) Verify(ctx context.Context, m *Message) error {
+	stringToSign, err := m.StringToSign()
+	if err != nil {
+		return err
+	}
+
+	if err := v.checkTimestamp(m.Timestamp); err != nil {
+		return err
+	}
+
+	var hash crypto.Hash
+	switch m.SignatureVersion {
+	case "1":
+		hash = crypto.SHA1
+	case "2":
+		hash = crypto.SHA256
+	default:
+		return fmt.Errorf("unsupported SNS signature version %q", m.SignatureVersion)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(m.Signature)
+	if err != nil {
+		return fmt.Errorf("SNS signature is not valid base64: %w", err)
+	}
+
+	certificate, err := v.certificate(ctx, m.SigningCertURL)
+	if err != nil {
+		return err
+	}
+
+	publicKey, ok := certificate.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return errors.New("SNS signing certificate doesn't hold an RSA key")
+	}
+
+	if err := rsa.VerifyPKCS1v15(publicKey, hash, digest(hash, stringToSign), signature); err != nil {
+		return fmt.Errorf("invalid SNS signature: %w", err)
+	}
+
+	return nil
+}
+
+// ConfirmSubscription visits the SubscribeURL of a verified subscription confirmation
+func (v *Verifier) ConfirmSubscription(ctx context.Context, m *Message) error {
+	if m.Type != TypeSubscriptionConfirmation {
+		return fmt.Errorf("SNS message %s is a %s, not a %s", m.MessageId, m.Type, TypeSubscriptionConfirmation)
+	}
+
+	if err := v.checkURL(m.SubscribeURL); err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, m.SubscribeURL, nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := v.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to confirm SNS subscription: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to confirm SNS subscription, status %d", response.StatusCode)
+	}
+
+	return nil
+}
+
+func (v *Verifier) certificate(ctx context.Context, certificateURL string) (*x509.Certificate, error) {
+	if err := v.checkURL(certificateURL); err != nil {
+		return nil, err
+	}
+
+	v.mu.Lock()
+	certificate, ok := v.certificates[certificateURL]
+	v.mu.Unlock()
+
+	if !ok {
+		var err error
+		certificate, err = v.download(ctx, certificateURL)
+		if err != nil {
+			return nil, err
+		}
+
+		v.mu.Lock()
+		v.certificates[certificateURL] = certificate
+		v.mu.Unlock()
+	}
+
+	now := v.now()
+	if now.Before(certificate.NotBefore) || now.After(certificate.NotAfter) {
+		return nil, fmt.Errorf("SNS signing certificate %s is not valid at %s", certificateURL, now.Format(time.RFC3339))
+	}
+
+	return certificate, nil
+}
+
+func (v *Verifier) download(ctx context.Context, certificateURL string) (*x509.Certificate, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, certificateURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := v.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download SNS signing certificate: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download SNS signing certificate, status %d", response.StatusCode)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(response.Body, maxCertificateSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download SNS signing certificate: %w", err)
+	}
+
+	block, _ := pem.Decode(content)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("SNS signing certificate is not a PEM encoded certificate")
+	}
+
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func (v *Verifier) checkTimestamp(timestamp string) error {
+	sentAt, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return fmt.Errorf("invalid SNS message timestamp %q: %w", timestamp, err)
+	}
+
+	maxAge := v.MaxMessageAge
+	if maxAge <= 0 {
+		maxAge = DefaultMaxMessageAge
+	}
+
+	now := v.now()
+	if sentAt.Before(now.Add(-maxAge)) || sentAt.After(now.Add(maxClockSkew)) {
+		return fmt.Errorf("SNS message timestamp %s is outside the accepted window", timestamp)
+	}
+
+	return nil
+}
+
+// checkURL makes sure a URL from a message points to SNS, so a forged message can't make us fetch arbitrary URLs
+func (v *Verifier) checkURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid SNS URL %q: %w", rawURL, err)
+	}
+
+	if parsed.Scheme != "https" || !v.hosts.MatchString(parsed.Hostname()) {
+		return fmt.Errorf("SNS URL %q is not an HTTPS URL of an allowed host", rawURL)
+	}
+
+	return nil
+}
+
+func digest(hash crypto.Hash, content string) []byte {
+	hasher := hash.New()
+	hasher.Write([]byte(content))
+
+	return hasher.Sum(nil)
+}
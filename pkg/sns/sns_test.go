@@ -0,0 +1,100 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package sns_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"session-dsm-grpc-plugin/pkg/sns"
+	"session-dsm-grpc-plugin/pkg/sns/snstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func notification(signatureVersion string) *sns.Message {
+	return &sns.Message{
+		Type:             sns.TypeNotification,
+		MessageId:        "message-1",
+		TopicArn:         "arn:aws:sns:us-west-2:123456789012:placements",
+		Message:          `{"detail":{"type":"PlacementFulfilled"}}`,
+		Timestamp:        time.Now().UTC().Format(time.RFC3339Nano),
+		SignatureVersion: signatureVersion,
+	}
+}
+
+func TestVerify(t *testing.T) {
+	server := snstest.NewServer(t)
+	verifier := server.Verifier()
+
+	for _, signatureVersion := range []string{"1", "2"} {
+		m := notification(signatureVersion)
+		server.Sign(t, m)
+		assert.NoError(t, verifier.Verify(context.Background(), m), "signature version %s", signatureVersion)
+	}
+
+	tampered := notification("2")
+	server.Sign(t, tampered)
+	tampered.Message = `{"detail":{"type":"PlacementFailed"}}`
+	assert.Error(t, verifier.Verify(context.Background(), tampered))
+
+	unsigned := notification("2")
+	server.Sign(t, unsigned)
+	unsigned.SignatureVersion = "3"
+	assert.Error(t, verifier.Verify(context.Background(), unsigned))
+}
+
+func TestVerifyRejectsStaleMessages(t *testing.T) {
+	server := snstest.NewServer(t)
+	verifier := server.Verifier()
+	verifier.MaxMessageAge = time.Minute
+
+	for _, sentAt := range []time.Time{time.Now().Add(-2 * time.Minute), time.Now().Add(time.Hour)} {
+		m := notification("2")
+		m.Timestamp = sentAt.UTC().Format(time.RFC3339Nano)
+		server.Sign(t, m)
+		assert.Error(t, verifier.Verify(context.Background(), m), "timestamp %s", m.Timestamp)
+	}
+
+	m := notification("2")
+	m.Timestamp = "yesterday"
+	server.Sign(t, m)
+	assert.Error(t, verifier.Verify(context.Background(), m))
+}
+
+func TestVerifyRejectsCertificatesFromOtherHosts(t *testing.T) {
+	server := snstest.NewServer(t)
+
+	m := notification("2")
+	server.Sign(t, m)
+
+	assert.Error(t, sns.NewVerifier(server.Client(), nil).Verify(context.Background(), m))
+
+	m.SigningCertURL = "http://127.0.0.1" + snstest.CertificatePath
+	assert.Error(t, server.Verifier().Verify(context.Background(), m))
+}
+
+func TestConfirmSubscription(t *testing.T) {
+	server := snstest.NewServer(t)
+	verifier := server.Verifier()
+
+	m := &sns.Message{
+		Type:             sns.TypeSubscriptionConfirmation,
+		MessageId:        "message-1",
+		Token:            "token",
+		TopicArn:         "arn:aws:sns:us-west-2:123456789012:placements",
+		Message:          "You have chosen to subscribe to the topic",
+		SubscribeURL:     server.URL + snstest.ConfirmPath,
+		Timestamp:        time.Now().UTC().Format(time.RFC3339Nano),
+		SignatureVersion: "1",
+	}
+	server.Sign(t, m)
+
+	require.NoError(t, verifier.Verify(context.Background(), m))
+	require.NoError(t, verifier.ConfirmSubscription(context.Background(), m))
+	assert.True(t, server.Confirmed())
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2024 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+// Package snstest signs SNS messages with a locally generated certificate, for tests of SNS subscribers
+package snstest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha1" //nolint:gosec // SNS signs messages with SHA1 when the topic uses SignatureVersion 1
+	_ "crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"session-dsm-grpc-plugin/pkg/sns"
+
+	"github.com/stretchr/testify/require"
+)
+
+// CertificatePath is the path the signing certificate is served from
+const CertificatePath = "/SimpleNotificationService.pem"
+
+// ConfirmPath is the path that records subscription confirmations
+const ConfirmPath = "/confirm"
+
+// Server serves a locally generated signing certificate over HTTPS, standing in for SNS
+type Server struct {
+	*httptest.Server
+
+	key *rsa.PrivateKey
+
+	mu        sync.Mutex
+	confirmed bool
+}
+
+// NewServer starts a server that is closed when the test ends
+func NewServer(t testing.TB) *Server {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sns.us-west-2.amazonaws.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certificatePEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate})
+
+	s := &Server{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc(CertificatePath, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(certificatePEM)
+	})
+	mux.HandleFunc(ConfirmPath, func(w http.ResponseWriter, _ *http.Request) {
+		s.mu.Lock()
+		s.confirmed = true
+		s.mu.Unlock()
+	})
+	s.Server = httptest.NewTLSServer(mux)
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// Verifier returns a verifier that trusts the certificates of the server
+func (s *Server) Verifier() *sns.Verifier {
+	return sns.NewVerifier(s.Client(), regexp.MustCompile(`^127\.0\.0\.1$`))
+}
+
+// Sign signs the message with the certificate of the server, using SHA1 for SignatureVersion 1 and SHA256 otherwise
+func (s *Server) Sign(t testing.TB, m *sns.Message) {
+	m.SigningCertURL = s.URL + CertificatePath
+
+	hash := crypto.SHA256
+	if m.SignatureVersion == "1" {
+		hash = crypto.SHA1
+	}
+
+	stringToSign, err := m.StringToSign()
+	require.NoError(t, err)
+
+	hasher := hash.New()
+	hasher.Write([]byte(stringToSign))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, hash, hasher.Sum(nil))
+	require.NoError(t, err)
+	m.Signature = base64.StdEncoding.EncodeToString(signature)
+}
+
+// Confirmed reports whether a subscription was confirmed through ConfirmPath
+func (s *Server) Confirmed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.confirmed
+}